@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"log"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,12 +12,17 @@ import (
 	"github.com/bwmarrin/discordgo"
 	"github.com/ekkolyth/ekko-bot/internal/api/handlers"
 	"github.com/ekkolyth/ekko-bot/internal/api/httpserver"
+	"github.com/ekkolyth/ekko-bot/internal/bus"
 	"github.com/ekkolyth/ekko-bot/internal/cache"
+	"github.com/ekkolyth/ekko-bot/internal/config"
 	appctx "github.com/ekkolyth/ekko-bot/internal/context"
 	"github.com/ekkolyth/ekko-bot/internal/db"
+	"github.com/ekkolyth/ekko-bot/internal/health"
 	"github.com/ekkolyth/ekko-bot/internal/logging"
 	"github.com/ekkolyth/ekko-bot/internal/lua"
+	"github.com/ekkolyth/ekko-bot/internal/metrics"
 	"github.com/ekkolyth/ekko-bot/internal/music"
+	"github.com/ekkolyth/ekko-bot/internal/ratelimit"
 	"github.com/joho/godotenv"
 )
 
@@ -38,18 +42,19 @@ func main() {
 
 	if _, err := os.Stat(".env.local"); err == nil {
 		if err := godotenv.Load(".env.local"); err != nil {
-			log.Println("Warning: .env present but could not be loaded:", err)
+			logging.Warning(".env present but could not be loaded: " + err.Error())
 		}
 	}
+	logging.Configure()
 
 	port := getenvDefault("API_PORT", "1337")
 	if _, err := strconv.Atoi(port); err != nil {
-		log.Fatal("Invalid API_PORT value:", port)
+		logging.Fatal("Invalid API_PORT value: "+port, nil)
 	}
 
 	// Initialize Lua VM
 	if err := lua.Init(); err != nil {
-		log.Fatal("Failed to initialize Lua VM:", err)
+		logging.Fatal("Failed to initialize Lua VM", err)
 	}
 	defer lua.Close()
 
@@ -58,7 +63,7 @@ func main() {
 	// Redis init
 	redisClient, err := cache.InitRedis()
 	if err != nil {
-		log.Fatal("Failed to connect to redis:", err)
+		logging.Fatal("Failed to connect to redis", err)
 	}
 	appctx.SetQueueStore(appctx.NewRedisQueueStore(redisClient))
 	defer cache.CloseRedis(ctx)
@@ -66,26 +71,49 @@ func main() {
 	// DB init
 	dbService, err := db.NewService(ctx)
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		logging.Fatal("Failed to connect to database", err)
 	}
 	defer dbService.DB.Close()
 	logging.Info("Database connection established")
 	music.SetService(music.NewService(dbService.DB))
+	music.SetGuildConfigService(dbService.GuildConfig)
+
+	// Drop cached Discord identities on every replica when a user relinks
+	accounts := dbService.DiscordAccounts
+	accounts.SetLinkNotifier(func(appUserID string) {
+		cache.PublishIdentityLinked(redisClient, appUserID)
+	})
+	if err := cache.SubscribeIdentityLinked(ctx, redisClient, accounts.Forget); err != nil {
+		logging.Fatal("Failed to subscribe to identity links", err)
+	}
+
+	if config.APIAuthSecret() == "" {
+		logging.Fatal("API_AUTH_SECRET must be set to authenticate requests from the web app", nil)
+	}
 
 	// Discord
 	discordToken := os.Getenv("DISCORD_BOT_TOKEN")
 	if discordToken == "" {
-		logging.Fatal("[API] DISCORD_BOT_TOKEN not found - check .env file", err)
+		logging.Fatal("[API] DISCORD_BOT_TOKEN not found - check .env file", nil)
 	}
 	dg, err := discordgo.New("Bot " + discordToken)
 	if err != nil {
-		log.Fatal("Error creating Discord session:", err)
+		logging.Fatal("Error creating Discord session", err)
 	}
 	if err := dg.Open(); err != nil {
-		log.Fatal("Error opening Discord connection:", err)
+		logging.Fatal("Error opening Discord connection", err)
 	}
 	defer dg.Close()
 	handlers.SetDiscordSession(dg)
+	handlers.SetCommandBus(bus.NewClient(redisClient))
+	handlers.SetRateLimiter(ratelimit.NewLimiter(redisClient))
+	handlers.SetReadinessChecks(
+		health.Redis(redisClient),
+		health.Postgres(dbService.DB.Pool),
+		health.DiscordGateway(dg),
+		health.Binary("yt-dlp"),
+		health.Binary("ffmpeg"),
+	)
 
 	router := httpserver.NewRouter(dbService)
 	server := &http.Server{
@@ -96,23 +124,35 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// Optional prometheus listener, kept off the public API port
+	if metricsAddr := config.APIMetricsAddr(); metricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", metrics.Handler())
+		go func() {
+			logging.Info("Metrics listener on %s", metricsAddr)
+			if err := http.ListenAndServe(metricsAddr, mux); err != nil {
+				logging.Error("Metrics listener stopped: " + err.Error())
+			}
+		}()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
 		logging.Info("✅ API listening on :%s (all interfaces)", port)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logging.Fatal("%s", err)
+			logging.Fatal("API server stopped", err)
 		}
 	}()
 
 	<-quit
-	log.Println("Server is shutting down...")
+	logging.Info("Server is shutting down...")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 	if err := server.Shutdown(ctx); err != nil {
-		logging.Fatal("Server forced to shutdown:", err)
+		logging.Fatal("Server forced to shutdown", err)
 	}
-	log.Println("Server exited")
+	logging.Info("Server exited")
 }
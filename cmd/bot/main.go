@@ -2,17 +2,24 @@ package main
 
 import (
 	stdctx "context"
+	"net/http"
 	"os"
 	"os/exec"
+	"os/signal"
 	"strings"
+	"syscall"
 
+	"github.com/ekkolyth/ekko-bot/internal/bus"
 	"github.com/ekkolyth/ekko-bot/internal/cache"
+	"github.com/ekkolyth/ekko-bot/internal/config"
 	"github.com/ekkolyth/ekko-bot/internal/context"
 	"github.com/ekkolyth/ekko-bot/internal/db"
 	"github.com/ekkolyth/ekko-bot/internal/discord"
 	"github.com/ekkolyth/ekko-bot/internal/handlers"
+	"github.com/ekkolyth/ekko-bot/internal/health"
 	"github.com/ekkolyth/ekko-bot/internal/logging"
 	"github.com/ekkolyth/ekko-bot/internal/lua"
+	"github.com/ekkolyth/ekko-bot/internal/metrics"
 	"github.com/ekkolyth/ekko-bot/internal/music"
 
 	"github.com/bwmarrin/discordgo"
@@ -27,6 +34,8 @@ func setup() {
 			logging.Fatal("Error loading .env file", err)
 		}
 	}
+	logging.Configure()
+
 	//Check Discord Token
 	context.Token = os.Getenv("DISCORD_BOT_TOKEN")
 	if context.Token == "" {
@@ -75,6 +84,8 @@ func main() {
 	}
 	defer dbService.DB.Close()
 	music.SetService(music.NewService(dbService.DB))
+	music.SetGuildConfigService(dbService.GuildConfig)
+	music.SetRadioStationService(dbService.RadioStations)
 	handlers.SetCustomCommandService(dbService.CustomCommands)
 	handlers.SetGuildConfigService(dbService.GuildConfig)
 
@@ -90,6 +101,10 @@ func main() {
 	dg.AddHandler(handlers.HandleInteractionCreate)
 	dg.AddHandler(handlers.HandleVoiceStateUpdate)
 	dg.AddHandler(handlers.HandleGuildMemberAdd)
+	dg.AddHandler(handlers.HandleGuildCreate)
+
+	// Clear playing flags left by a previous run before the gateway delivers guilds
+	music.ReconcileQueues(config.ResumeQueues())
 
 	err = dg.Open()
 
@@ -99,7 +114,63 @@ func main() {
 		logging.Fatal("Error opening connection", err)
 	}
 	defer dg.Close()
+
+	// Execute playback commands sent by the API
+	busCtx, stopBus := stdctx.WithCancel(stdctx.Background())
+	defer stopBus()
+	go bus.Serve(busCtx, redisClient, handlers.HandleBusCommand(dg))
+
+	metrics.RegisterVoiceConnections(func() int {
+		dg.RLock()
+		defer dg.RUnlock()
+		return len(dg.VoiceConnections)
+	})
+
+	// Internal health listener so the orchestrator can restart a dead gateway
+	healthAddr := config.BotHealthAddr()
+	metricsAddr := config.BotMetricsAddr()
+	if healthAddr != "" {
+		mux := health.NewMux(
+			health.Redis(redisClient),
+			health.Postgres(dbService.DB.Pool),
+			health.DiscordGateway(dg),
+			health.Binary("yt-dlp"),
+			health.Binary("ffmpeg"),
+		)
+		if metricsAddr == healthAddr {
+			mux.Handle("GET /metrics", metrics.Handler())
+			metricsAddr = ""
+		}
+		go func() {
+			logging.Info("Health listener on %s", healthAddr)
+			if err := http.ListenAndServe(healthAddr, mux); err != nil {
+				logging.Error("Health listener stopped: " + err.Error())
+			}
+		}()
+	}
+
+	// Optional prometheus listener
+	if metricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", metrics.Handler())
+		go func() {
+			logging.Info("Metrics listener on %s", metricsAddr)
+			if err := http.ListenAndServe(metricsAddr, mux); err != nil {
+				logging.Error("Metrics listener stopped: " + err.Error())
+			}
+		}()
+	}
+
 	logging.Info("Version: " + context.GoSourceHash)
 	logging.Info("Bot is running. Press CTRL-C to exit.")
-	select {} // block forever
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	// Stop taking commands, then save and stop every player before the deferred closes run
+	logging.Info("Bot is shutting down...")
+	stopBus()
+	music.Shutdown(dg, config.ShutdownTimeout())
+	logging.Info("Bot exited")
 }
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	stdctx "context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ekkolyth/ekko-bot/internal/api/httpx"
+	"github.com/ekkolyth/ekko-bot/internal/config"
+	"github.com/ekkolyth/ekko-bot/internal/db"
+	"github.com/ekkolyth/ekko-bot/internal/logging"
+)
+
+type callerKey struct{}
+
+// Authenticate verifies the token signed by the web app and looks up the caller's
+// Discord account in user_discord_account. It never writes, accounts are linked
+// once through LinkAccount when the web app issues its first token for a user.
+func Authenticate(accounts *db.UserDiscordAccountService) func(http.Handler) http.Handler {
+	return authenticate(accounts, false)
+}
+
+// AuthenticateStream is Authenticate for event streams. EventSource clients
+// can't set headers, so the token may also come from the access_token query
+// parameter. Keep it to those routes, query strings end up in access logs.
+func AuthenticateStream(accounts *db.UserDiscordAccountService) func(http.Handler) http.Handler {
+	return authenticate(accounts, true)
+}
+
+func authenticate(accounts *db.UserDiscordAccountService, allowQuery bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(write http.ResponseWriter, read *http.Request) {
+			claims, ok := verifyRequestToken(write, read, accounts, allowQuery)
+			if !ok {
+				return
+			}
+
+			identity, err := accounts.Lookup(read.Context(), claims.Subject)
+			if err != nil {
+				if errors.Is(err, db.ErrDiscordAccountNotLinked) {
+					httpx.RespondError(write, http.StatusForbidden, "No Discord account is linked to this user")
+					return
+				}
+				httpx.RespondError(write, http.StatusInternalServerError, "Failed to resolve Discord account")
+				return
+			}
+			if claims.DiscordUserID != "" && claims.DiscordUserID != identity.DiscordUserID {
+				// relinked in the web app, LinkAccount hasn't caught up yet
+				httpx.RespondError(write, http.StatusForbidden, "Discord account link is out of date")
+				return
+			}
+
+			ctx := stdctx.WithValue(read.Context(), callerKey{}, identity)
+			ctx = logging.WithAttrs(ctx, "user_id", identity.DiscordUserID)
+			next.ServeHTTP(write, read.WithContext(ctx))
+		})
+	}
+}
+
+// LinkAccount maps the token's subject to its discord_user_id claim. The web app
+// calls it when it issues a token for a user it hasn't linked yet.
+func LinkAccount(accounts *db.UserDiscordAccountService) http.HandlerFunc {
+	return func(write http.ResponseWriter, read *http.Request) {
+		claims, ok := verifyRequestToken(write, read, accounts, false)
+		if !ok {
+			return
+		}
+		if claims.DiscordUserID == "" {
+			httpx.RespondError(write, http.StatusBadRequest, "Token has no discord_user_id")
+			return
+		}
+
+		identity, err := accounts.Link(read.Context(), claims.Subject, claims.DiscordUserID)
+		if err != nil {
+			if errors.Is(err, db.ErrDiscordAccountNotLinked) {
+				httpx.RespondError(write, http.StatusBadRequest, "Token has no subject")
+				return
+			}
+			httpx.RespondError(write, http.StatusInternalServerError, "Failed to link Discord account")
+			return
+		}
+
+		httpx.RespondJSON(write, http.StatusOK, map[string]string{
+			"discord_user_id": identity.DiscordUserID,
+			"display_tag":     identity.DisplayTag,
+		})
+	}
+}
+
+// verify the request's token, responding with the error when it isn't valid
+func verifyRequestToken(write http.ResponseWriter, read *http.Request, accounts *db.UserDiscordAccountService, allowQuery bool) (*httpx.TokenClaims, bool) {
+	secret := config.APIAuthSecret()
+	if secret == "" || accounts == nil {
+		httpx.RespondError(write, http.StatusInternalServerError, "API authentication is not configured")
+		return nil, false
+	}
+
+	token := bearerToken(read, allowQuery)
+	if token == "" {
+		write.Header().Set("WWW-Authenticate", "Bearer")
+		httpx.RespondError(write, http.StatusUnauthorized, "Missing bearer token")
+		return nil, false
+	}
+
+	claims, err := httpx.VerifyToken([]byte(secret), token, time.Now())
+	if err != nil {
+		write.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		httpx.RespondError(write, http.StatusUnauthorized, "Invalid token: "+err.Error())
+		return nil, false
+	}
+	return claims, true
+}
+
+// return the token from the Authorization header, or from access_token when
+// allowQuery is set
+func bearerToken(read *http.Request, allowQuery bool) string {
+	header := read.Header.Get("Authorization")
+	if scheme, token, ok := strings.Cut(header, " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	if !allowQuery {
+		return ""
+	}
+	return strings.TrimSpace(read.URL.Query().Get("access_token"))
+}
+
+// return the caller resolved by Authenticate
+func callerFromRequest(read *http.Request) *db.DiscordIdentity {
+	identity, _ := read.Context().Value(callerKey{}).(*db.DiscordIdentity)
+	return identity
+}
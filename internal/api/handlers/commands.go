@@ -34,11 +34,7 @@ type commandUpdateRequest struct {
 // CommandsList returns all saved commands for the configured guild.
 func CommandsList(service *db.CustomCommandService) http.HandlerFunc {
 	return func(write http.ResponseWriter, read *http.Request) {
-		guildID, errMsg := getGuildID()
-		if errMsg != "" {
-			httpx.RespondError(write, http.StatusInternalServerError, errMsg)
-			return
-		}
+		guildID := guildFromRequest(read)
 
 		commands, err := service.List(read.Context(), guildID)
 		if err != nil {
@@ -53,11 +49,7 @@ func CommandsList(service *db.CustomCommandService) http.HandlerFunc {
 // CommandsCreate persists a new command name/response pair.
 func CommandsCreate(service *db.CustomCommandService) http.HandlerFunc {
 	return func(write http.ResponseWriter, read *http.Request) {
-		guildID, errMsg := getGuildID()
-		if errMsg != "" {
-			httpx.RespondError(write, http.StatusInternalServerError, errMsg)
-			return
-		}
+		guildID := guildFromRequest(read)
 
 		defer read.Body.Close()
 
@@ -89,11 +81,7 @@ func CommandsCreate(service *db.CustomCommandService) http.HandlerFunc {
 // CommandsUpdate mutates an existing command name/response.
 func CommandsUpdate(service *db.CustomCommandService) http.HandlerFunc {
 	return func(write http.ResponseWriter, read *http.Request) {
-		guildID, errMsg := getGuildID()
-		if errMsg != "" {
-			httpx.RespondError(write, http.StatusInternalServerError, errMsg)
-			return
-		}
+		guildID := guildFromRequest(read)
 
 		commandID := chi.URLParam(read, "id")
 		if commandID == "" {
@@ -135,11 +123,7 @@ func CommandsUpdate(service *db.CustomCommandService) http.HandlerFunc {
 // CommandsDelete removes a command for the configured guild.
 func CommandsDelete(service *db.CustomCommandService) http.HandlerFunc {
 	return func(write http.ResponseWriter, read *http.Request) {
-		guildID, errMsg := getGuildID()
-		if errMsg != "" {
-			httpx.RespondError(write, http.StatusInternalServerError, errMsg)
-			return
-		}
+		guildID := guildFromRequest(read)
 
 		commandID := chi.URLParam(read, "id")
 		if commandID == "" {
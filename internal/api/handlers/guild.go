@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	stdctx "context"
+	"errors"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/ekkolyth/ekko-bot/internal/api/httpx"
+	"github.com/ekkolyth/ekko-bot/internal/config"
+	"github.com/ekkolyth/ekko-bot/internal/logging"
+	"github.com/go-chi/chi/v5"
+)
+
+type guildIDKey struct{}
+type memberKey struct{}
+
+var errNotGuildMember = errors.New("not a guild member")
+
+// members fetched over REST are reused briefly so every request doesn't hit Discord
+const memberCacheTTL = 30 * time.Second
+
+type cachedMember struct {
+	member  *discordgo.Member
+	expires time.Time
+}
+
+var (
+	memberCacheMu sync.Mutex
+	memberCache   = make(map[string]cachedMember)
+)
+
+// RequireGuild resolves {guildID} from the route and rejects guilds the bot or caller is not in
+func RequireGuild() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(write http.ResponseWriter, read *http.Request) {
+			guildID := chi.URLParam(read, "guildID")
+			if !httpx.ValidDiscordSnowflake(guildID) {
+				httpx.RespondError(write, http.StatusBadRequest, "Invalid guild ID: must be a valid Discord snowflake")
+				return
+			}
+
+			serveGuild(write, read, next, guildID)
+		})
+	}
+}
+
+// DefaultGuild serves the unscoped routes from DISCORD_GUILD_ID for single guild setups
+func DefaultGuild() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(write http.ResponseWriter, read *http.Request) {
+			guildID, errMsg := getGuildID()
+			if errMsg != "" {
+				httpx.RespondError(write, http.StatusNotFound, errMsg+"; use /api/guilds/{guildID} routes instead")
+				return
+			}
+
+			serveGuild(write, read, next, guildID)
+		})
+	}
+}
+
+// RequireWritePermission lets reads through and needs Manage Server or the DJ role for everything else
+func RequireWritePermission() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(write http.ResponseWriter, read *http.Request) {
+			switch read.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(write, read)
+				return
+			}
+
+			session := discordSession()
+			member, _ := read.Context().Value(memberKey{}).(*discordgo.Member)
+			if session == nil || member == nil {
+				httpx.RespondError(write, http.StatusForbidden, "Guild membership could not be verified")
+				return
+			}
+
+			guild, err := lookupGuild(session, guildFromRequest(read))
+			if err != nil {
+				httpx.RespondError(write, http.StatusBadGateway, "Failed to look up guild")
+				return
+			}
+
+			if !canControl(guild, member) {
+				httpx.RespondError(write, http.StatusForbidden, "Manage Server permission or the "+config.DJRoleName()+" role is required")
+				return
+			}
+
+			next.ServeHTTP(write, read)
+		})
+	}
+}
+
+// getGuildID reads and validates DISCORD_GUILD_ID from environment
+// Returns the guild ID and an error message if validation fails (empty string = no error)
+func getGuildID() (string, string) {
+	guildID := os.Getenv("DISCORD_GUILD_ID")
+	if guildID == "" {
+		return "", "Missing DISCORD_GUILD_ID environment variable"
+	}
+
+	if !httpx.ValidDiscordSnowflake(guildID) {
+		return "", "Invalid DISCORD_GUILD_ID: must be a valid Discord snowflake (numeric, not \"_\")"
+	}
+
+	return guildID, ""
+}
+
+// check the bot and caller are in the guild and pass both on in the request context
+func serveGuild(write http.ResponseWriter, read *http.Request, next http.Handler, guildID string) {
+	session := discordSession()
+	if session == nil {
+		httpx.RespondError(write, http.StatusInternalServerError, "Discord session unavailable")
+		return
+	}
+
+	if _, err := lookupGuild(session, guildID); err != nil {
+		if restStatus(err, http.StatusForbidden, http.StatusNotFound) {
+			httpx.RespondError(write, http.StatusNotFound, "Bot is not a member of this guild")
+			return
+		}
+		httpx.RespondError(write, http.StatusBadGateway, "Failed to look up guild")
+		return
+	}
+
+	caller := callerFromRequest(read)
+	if caller == nil {
+		httpx.RespondError(write, http.StatusUnauthorized, "Missing caller identity")
+		return
+	}
+
+	member, err := guildMember(session, guildID, caller.DiscordUserID)
+	if err != nil {
+		if errors.Is(err, errNotGuildMember) {
+			httpx.RespondError(write, http.StatusForbidden, "You are not a member of this guild")
+			return
+		}
+		httpx.RespondError(write, http.StatusBadGateway, "Failed to look up guild member")
+		return
+	}
+
+	write.Header().Set("X-Guild-ID", guildID)
+	ctx := stdctx.WithValue(read.Context(), guildIDKey{}, guildID)
+	ctx = stdctx.WithValue(ctx, memberKey{}, member)
+	ctx = logging.WithAttrs(ctx, "guild_id", guildID)
+	next.ServeHTTP(write, read.WithContext(ctx))
+}
+
+// return the guild resolved by RequireGuild or DefaultGuild
+func guildFromRequest(read *http.Request) string {
+	guildID, _ := read.Context().Value(guildIDKey{}).(string)
+	return guildID
+}
+
+// return the API's Discord session, nil until SetDiscordSession is called
+func discordSession() *discordgo.Session {
+	if discordSessionProvider == nil {
+		return nil
+	}
+	session, _ := discordSessionProvider().(*discordgo.Session)
+	return session
+}
+
+// return the guild from gateway state, falling back to REST when the state
+// hasn't caught up yet
+func lookupGuild(session *discordgo.Session, guildID string) (*discordgo.Guild, error) {
+	if guild, err := session.State.Guild(guildID); err == nil && guild != nil {
+		return guild, nil
+	}
+	return session.Guild(guildID)
+}
+
+// return the guild member from state or REST, errNotGuildMember if they aren't in it
+func guildMember(session *discordgo.Session, guildID, userID string) (*discordgo.Member, error) {
+	if member, err := session.State.Member(guildID, userID); err == nil && member != nil {
+		return member, nil
+	}
+
+	key := guildID + ":" + userID
+	memberCacheMu.Lock()
+	cached, ok := memberCache[key]
+	if ok && time.Now().After(cached.expires) {
+		delete(memberCache, key)
+		ok = false
+	}
+	memberCacheMu.Unlock()
+	if ok {
+		return cached.member, nil
+	}
+
+	member, err := session.GuildMember(guildID, userID)
+	if err != nil {
+		if restStatus(err, http.StatusNotFound) {
+			return nil, errNotGuildMember
+		}
+		return nil, err
+	}
+
+	memberCacheMu.Lock()
+	memberCache[key] = cachedMember{member: member, expires: time.Now().Add(memberCacheTTL)}
+	memberCacheMu.Unlock()
+	return member, nil
+}
+
+// report whether the member may change playback or guild settings
+func canControl(guild *discordgo.Guild, member *discordgo.Member) bool {
+	if memberPermissions(guild, member)&discordgo.PermissionManageGuild != 0 {
+		return true
+	}
+
+	djRole := config.DJRoleName()
+	for _, role := range guild.Roles {
+		if strings.EqualFold(role.Name, djRole) && slices.Contains(member.Roles, role.ID) {
+			return true
+		}
+	}
+	return false
+}
+
+// return the member's guild level permissions from their roles
+func memberPermissions(guild *discordgo.Guild, member *discordgo.Member) int64 {
+	if member.User != nil && guild.OwnerID == member.User.ID {
+		return discordgo.PermissionAll
+	}
+
+	var permissions int64
+	for _, role := range guild.Roles {
+		// the @everyone role shares the guild's ID
+		if role.ID == guild.ID || slices.Contains(member.Roles, role.ID) {
+			permissions |= role.Permissions
+		}
+	}
+
+	if permissions&discordgo.PermissionAdministrator != 0 {
+		return discordgo.PermissionAll
+	}
+	return permissions
+}
+
+// report whether err is a Discord REST error with one of the given statuses
+func restStatus(err error, statuses ...int) bool {
+	var restErr *discordgo.RESTError
+	if !errors.As(err, &restErr) || restErr.Response == nil {
+		return false
+	}
+	return slices.Contains(statuses, restErr.Response.StatusCode)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ekkolyth/ekko-bot/internal/api/httpx"
+	appdb "github.com/ekkolyth/ekko-bot/internal/db"
+)
+
+type guildConfigResponse struct {
+	DefaultVC *string `json:"default_vc"`
+	Volume    int     `json:"volume"`
+}
+
+type guildConfigRequest struct {
+	DefaultVC string `json:"default_vc"`
+	Volume    *int   `json:"volume"`
+}
+
+func GuildConfigGet(service *appdb.GuildConfigService) http.HandlerFunc {
+	return func(write http.ResponseWriter, read *http.Request) {
+		guildID := guildFromRequest(read)
+
+		if service == nil {
+			httpx.RespondError(write, http.StatusInternalServerError, "Guild config unavailable")
+			return
+		}
+
+		settings, err := service.GetPlaybackSettings(read.Context(), guildID)
+		if err != nil {
+			httpx.RespondError(write, http.StatusInternalServerError, "Failed to load guild config")
+			return
+		}
+
+		httpx.RespondJSON(write, http.StatusOK, guildConfigResponse{
+			DefaultVC: settings.DefaultVC,
+			Volume:    settings.Volume,
+		})
+	}
+}
+
+func GuildConfigSave(service *appdb.GuildConfigService) http.HandlerFunc {
+	return func(write http.ResponseWriter, read *http.Request) {
+		guildID := guildFromRequest(read)
+
+		if service == nil {
+			httpx.RespondError(write, http.StatusInternalServerError, "Guild config unavailable")
+			return
+		}
+
+		var payload guildConfigRequest
+		if err := httpx.DecodeJSON(write, read, &payload, 1<<20); err != nil {
+			httpx.RespondError(write, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if payload.Volume == nil {
+			httpx.RespondError(write, http.StatusBadRequest, "Missing volume")
+			return
+		}
+
+		if payload.DefaultVC != "" && !httpx.ValidDiscordSnowflake(payload.DefaultVC) {
+			httpx.RespondError(write, http.StatusBadRequest, "Invalid default_vc")
+			return
+		}
+
+		settings, err := service.SavePlaybackSettings(read.Context(), guildID, payload.DefaultVC, *payload.Volume)
+		if err != nil {
+			switch {
+			case errors.Is(err, appdb.ErrGuildIDRequired):
+				httpx.RespondError(write, http.StatusInternalServerError, "Guild id missing")
+			case errors.Is(err, appdb.ErrVolumeOutOfRange):
+				httpx.RespondError(write, http.StatusBadRequest, "Volume must be between 0 and 200")
+			default:
+				httpx.RespondError(write, http.StatusInternalServerError, "Failed to save guild config")
+			}
+			return
+		}
+
+		httpx.RespondJSON(write, http.StatusOK, guildConfigResponse{
+			DefaultVC: settings.DefaultVC,
+			Volume:    settings.Volume,
+		})
+	}
+}
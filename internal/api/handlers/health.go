@@ -6,6 +6,7 @@ import (
 
 	"github.com/ekkolyth/ekko-bot/internal/api/httpx"
 	"github.com/ekkolyth/ekko-bot/internal/context"
+	"github.com/ekkolyth/ekko-bot/internal/health"
 	"github.com/ekkolyth/ekko-bot/internal/logging"
 )
 
@@ -18,3 +19,15 @@ func Health(w http.ResponseWriter, r *http.Request) {
 	logging.Api("Health Check OK")
 	httpx.RespondJSON(w, http.StatusOK, status)
 }
+
+var readinessChecks []health.Check // set via SetReadinessChecks
+
+// SetReadinessChecks wires the dependencies /api/readyz probes
+func SetReadinessChecks(checks ...health.Check) {
+	readinessChecks = checks
+}
+
+// Ready reports per dependency status and latency, 503 when any check fails
+func Ready(w http.ResponseWriter, r *http.Request) {
+	health.Handler(readinessChecks...)(w, r)
+}
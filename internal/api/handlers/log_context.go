@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/ekkolyth/ekko-bot/internal/logging"
+)
+
+// LogContext tags the request's log lines with the chi request ID. Guild and
+// user are added once RequireGuild and Authenticate have resolved them.
+func LogContext() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(write http.ResponseWriter, read *http.Request) {
+			ctx := logging.WithAttrs(read.Context(), "request_id", middleware.GetReqID(read.Context()))
+			next.ServeHTTP(write, read.WithContext(ctx))
+		})
+	}
+}
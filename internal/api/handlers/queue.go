@@ -1,35 +1,22 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
-	"os"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/ekkolyth/ekko-bot/internal/api/httpx"
+	"github.com/ekkolyth/ekko-bot/internal/bus"
 	appctx "github.com/ekkolyth/ekko-bot/internal/context"
-	"github.com/ekkolyth/ekko-bot/internal/lua"
 	"github.com/ekkolyth/ekko-bot/internal/logging"
 	"github.com/ekkolyth/ekko-bot/internal/music"
-	luaLib "github.com/yuin/gopher-lua"
+	"github.com/ekkolyth/ekko-bot/internal/provider"
+	"github.com/go-chi/chi/v5/middleware"
 )
 
-// getGuildID reads and validates DISCORD_GUILD_ID from environment
-// Returns the guild ID and an error message if validation fails (empty string = no error)
-func getGuildID() (string, string) {
-	guildID := os.Getenv("DISCORD_GUILD_ID")
-	if guildID == "" {
-		return "", "Missing DISCORD_GUILD_ID environment variable"
-	}
-
-	if !httpx.ValidDiscordSnowflake(guildID) {
-		return "", "Invalid DISCORD_GUILD_ID: must be a valid Discord snowflake (numeric, not \"_\")"
-	}
-
-	return guildID, ""
-}
-
 type queueAdd struct {
-	DiscordUserID  string `json:"discord_user_id"`
+	DiscordUserID  string `json:"discord_user_id"` // ignored, the requester comes from the auth token
 	DiscordTag     string `json:"discord_tag"`
 	VoiceChannelID string `json:"voice_channel_id"`
 	URL            string `json:"url"`
@@ -44,6 +31,13 @@ type queueTrack struct {
 	Thumbnail string `json:"thumbnail"`
 	AddedBy   string `json:"added_by"`
 	AddedByID string `json:"added_by_id"`
+
+	SpotifyURL string `json:"spotify_url,omitempty"` // set when the track was requested from Spotify
+	Live       bool   `json:"live"`                  // live stream or radio, duration is 0
+
+	// now playing entry only
+	PositionMS *int64 `json:"position_ms,omitempty"`
+	DurationMS *int64 `json:"duration_ms,omitempty"`
 }
 
 type queueResponse struct {
@@ -52,6 +46,7 @@ type queueResponse struct {
 	IsPlaying      bool         `json:"is_playing"`
 	IsPaused       bool         `json:"is_paused"`
 	Volume         float64      `json:"volume"`
+	LoopMode       string       `json:"loop_mode"`
 }
 
 type recentTrack struct {
@@ -70,6 +65,43 @@ func SetDiscordSession(session any) {
 	discordSessionProvider = func() any { return session }
 }
 
+var commandBus *bus.Client // set via SetCommandBus
+
+// SetCommandBus wires the client used to send playback commands to the bot
+func SetCommandBus(client *bus.Client) {
+	commandBus = client
+}
+
+// sendCommand forwards cmd to the bot process and responds with its reply
+func sendCommand(write http.ResponseWriter, read *http.Request, status int, cmd bus.Command, extra map[string]any) {
+	if commandBus == nil {
+		httpx.RespondError(write, http.StatusInternalServerError, "Command bus unavailable")
+		return
+	}
+
+	cmd.RequestID = middleware.GetReqID(read.Context())
+	reply, err := commandBus.Send(read.Context(), cmd)
+	if err != nil {
+		var commandErr *bus.CommandError
+		switch {
+		case errors.As(err, &commandErr):
+			httpx.RespondError(write, http.StatusConflict, commandErr.Message)
+		case errors.Is(err, bus.ErrNoReply):
+			httpx.RespondError(write, http.StatusGatewayTimeout, "Bot did not respond")
+		default:
+			logging.FromContext(read.Context()).Error("Failed to send command", "command", string(cmd.Type), "error", err)
+			httpx.RespondError(write, http.StatusBadGateway, "Failed to reach bot")
+		}
+		return
+	}
+
+	response := map[string]any{"ok": true, "messages": reply.Messages}
+	for key, value := range extra {
+		response[key] = value
+	}
+	httpx.RespondJSON(write, status, response)
+}
+
 func QueueGet() http.HandlerFunc {
 	return func(write http.ResponseWriter, read *http.Request) {
 		voiceChannelID := read.URL.Query().Get("voice_channel_id")
@@ -78,11 +110,7 @@ func QueueGet() http.HandlerFunc {
 			return
 		}
 
-		guildID, errMsg := getGuildID()
-		if errMsg != "" {
-			httpx.RespondError(write, http.StatusInternalServerError, errMsg)
-			return
-		}
+		guildID := guildFromRequest(read)
 
 		store := appctx.GetQueueStore()
 		if store == nil {
@@ -107,6 +135,11 @@ func QueueGet() http.HandlerFunc {
 			volume = 1.0
 		}
 
+		loopMode, err := store.GetLoopMode(queueKey)
+		if err != nil {
+			loopMode = appctx.LoopOff
+		}
+
 		nowPlayingInfo, err := store.GetNowPlaying(queueKey)
 		if err != nil {
 			httpx.RespondError(write, http.StatusInternalServerError, "Failed to read now playing info")
@@ -138,7 +171,23 @@ func QueueGet() http.HandlerFunc {
 
 		if nowPlayingInfo != nil {
 			meta := getMetadata(nowPlayingInfo.URL)
-			tracks = append(tracks, queueTrackFromInfo(position, nowPlayingInfo, meta))
+			track := queueTrackFromInfo(position, nowPlayingInfo, meta)
+
+			elapsedMS, startedAt, posErr := store.GetPosition(queueKey)
+			if posErr == nil {
+				// the stored position lags up to a second, extrapolate while playing
+				if isPlaying && !isPaused && !startedAt.IsZero() {
+					elapsedMS = time.Since(startedAt).Milliseconds()
+				}
+				durationMS := int64(track.Duration) * 1000
+				if durationMS > 0 && elapsedMS > durationMS {
+					elapsedMS = durationMS
+				}
+				track.PositionMS = &elapsedMS
+				track.DurationMS = &durationMS
+			}
+
+			tracks = append(tracks, track)
 			position++
 		}
 
@@ -154,6 +203,7 @@ func QueueGet() http.HandlerFunc {
 			IsPlaying:      isPlaying,
 			IsPaused:       isPaused,
 			Volume:         volume,
+			LoopMode:       string(loopMode),
 		}
 
 		httpx.RespondJSON(write, http.StatusOK, response)
@@ -168,11 +218,7 @@ func QueueRecent() http.HandlerFunc {
 			return
 		}
 
-		guildID, errMsg := getGuildID()
-		if errMsg != "" {
-			httpx.RespondError(write, http.StatusInternalServerError, errMsg)
-			return
-		}
+		guildID := guildFromRequest(read)
 
 		recent, err := music.List(read.Context(), guildID, voiceChannelID, 100)
 		if err != nil {
@@ -213,6 +259,9 @@ func queueTrackFromInfo(position int, info *appctx.TrackInfo, meta *appctx.Track
 		Thumbnail: "",
 		AddedBy:   info.AddedBy,
 		AddedByID: info.AddedByID,
+
+		SpotifyURL: info.SpotifyURL,
+		Live:       info.Live,
 	}
 
 	if info.Title != "" {
@@ -250,6 +299,12 @@ func queueTrackFromInfo(position int, info *appctx.TrackInfo, meta *appctx.Track
 		if meta.AddedByID != "" {
 			track.AddedByID = meta.AddedByID
 		}
+		if meta.Live {
+			track.Live = true
+		}
+		if meta.SpotifyURL != "" {
+			track.SpotifyURL = meta.SpotifyURL
+		}
 	}
 
 	if track.AddedBy == "" {
@@ -278,46 +333,26 @@ func QueueAdd() http.HandlerFunc {
 			return
 		}
 
-		// Validate required fields
-		if request.DiscordUserID == "" {
-			httpx.RespondError(write, http.StatusBadRequest, "Missing discord_user_id")
+		caller := callerFromRequest(read)
+		if caller == nil {
+			httpx.RespondError(write, http.StatusUnauthorized, "Missing caller identity")
 			return
 		}
-
-		if request.VoiceChannelID == "" {
-			httpx.RespondError(write, http.StatusBadRequest, "Missing voice_channel_id")
-			return
+		discordTag := request.DiscordTag
+		if discordTag == "" {
+			discordTag = caller.DisplayTag
 		}
 
-		// Normalize URL using Lua scripts (normalization includes validation)
-		script := lua.Get()
-		if err := script.LoadScript("lua/scripts/validate_url/validate_youtube_url.lua"); err != nil {
-			logging.Error("Failed to load normalization script: " + err.Error() + " - URL: " + request.URL)
-			httpx.RespondError(write, http.StatusBadRequest, "Invalid URL")
-			return
-		}
-
-		results, err := script.CallLuaFunc("validate_youtube_url", "normalize_youtube_url", 2, luaLib.LString(request.URL))
-		if err != nil {
-			logging.Error("Failed to normalize URL: " + err.Error() + " - URL: " + request.URL)
-			httpx.RespondError(write, http.StatusBadRequest, "Invalid URL")
-			return
-		}
-
-		if results[1].Type() != luaLib.LTNil {
-			logging.Error("Lua normalization error code: " + results[1].String() + " - URL: " + request.URL)
-			httpx.RespondError(write, http.StatusBadRequest, "Invalid URL")
-			return
-		}
-
-		if results[0].Type() == luaLib.LTNil {
-			logging.Error("Nil result from normalization - URL: " + request.URL)
-			httpx.RespondError(write, http.StatusBadRequest, "Invalid URL")
-			return
+		// Playlist URLs are expanded into individual tracks by AddSong
+		normalizedURL, isPlaylist := httpx.NormalizePlaylistURL(request.URL)
+		if !isPlaylist {
+			var ok bool
+			if normalizedURL, ok = provider.Normalize(request.URL); !ok {
+				httpx.RespondError(write, http.StatusBadRequest, "Invalid URL")
+				return
+			}
 		}
 
-		normalizedURL := results[0].String()
-
 		// Use Discord session directly in API
 		if discordSessionProvider == nil {
 			httpx.RespondError(write, http.StatusInternalServerError, "Discord session not initialized")
@@ -330,31 +365,32 @@ func QueueAdd() http.HandlerFunc {
 			return
 		}
 
-		guildID, errMsg := getGuildID()
-		if errMsg != "" {
-			httpx.RespondError(write, http.StatusInternalServerError, errMsg)
-			return
-		}
+		guildID := guildFromRequest(read)
 
-		// Build context and call AddSong directly
-		ctx := &appctx.Context{
-			SourceType:             appctx.SourceTypeWeb,
-			Session:                s,
-			GuildID:                guildID,
-			VoiceChannelID:         request.VoiceChannelID,
-			RequesterDiscordUserID: request.DiscordUserID,
-			RequesterTag:           request.DiscordTag,
-			Arguments:              map[string]string{"url": normalizedURL},
-			ArgumentsRaw:           make(map[string]any),
+		// Fall back to the requester's current channel, then the guild's default channel
+		if request.VoiceChannelID == "" {
+			request.VoiceChannelID = fallbackVoiceChannel(s, guildID, caller.DiscordUserID)
 		}
 
-		logging.Api("queue.add user:" + request.DiscordTag + request.DiscordUserID + " discord_tag=")
+		if request.VoiceChannelID == "" {
+			httpx.RespondError(write, http.StatusBadRequest, "Missing voice_channel_id")
+			return
+		}
 
-		music.AddSong(ctx, false, normalizedURL)
+		logging.FromContext(read.Context()).Info("queue.add", "discord_tag", discordTag, "voice_channel_id", request.VoiceChannelID)
 
-		httpx.RespondJSON(write, http.StatusCreated, map[string]any{
-			"ok":         true,
-			"youtubeUrl": normalizedURL,
+		// The bot process queues the song and joins voice
+		sendCommand(write, read, http.StatusCreated, bus.Command{
+			Type:           bus.CommandEnqueue,
+			GuildID:        guildID,
+			VoiceChannelID: request.VoiceChannelID,
+			UserID:         caller.DiscordUserID,
+			UserTag:        discordTag,
+			URL:            normalizedURL,
+		}, map[string]any{
+			"youtubeUrl": normalizedURL, // kept for older dashboards, the URL may not be YouTube
+			"url":        normalizedURL,
+			"playlist":   isPlaylist,
 		})
 	}
 }
@@ -372,11 +408,7 @@ func QueueRemove() http.HandlerFunc {
 			return
 		}
 
-		guildID, errMsg := getGuildID()
-		if errMsg != "" {
-			httpx.RespondError(write, http.StatusInternalServerError, errMsg)
-			return
-		}
+		guildID := guildFromRequest(read)
 
 		store := appctx.GetQueueStore()
 		if store == nil {
@@ -416,6 +448,69 @@ func QueueRemove() http.HandlerFunc {
 	}
 }
 
+func QueueMove() http.HandlerFunc {
+	return func(write http.ResponseWriter, read *http.Request) {
+		type moveRequest struct {
+			VoiceChannelID string `json:"voice_channel_id"`
+			From           int    `json:"from"`
+			To             int    `json:"to"`
+		}
+
+		var request moveRequest
+		if err := httpx.DecodeJSON(write, read, &request, 1<<20); err != nil {
+			httpx.RespondError(write, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if request.VoiceChannelID == "" {
+			httpx.RespondError(write, http.StatusBadRequest, "Missing voice_channel_id")
+			return
+		}
+
+		guildID := guildFromRequest(read)
+
+		store := appctx.GetQueueStore()
+		if store == nil {
+			httpx.RespondError(write, http.StatusInternalServerError, "Queue store unavailable")
+			return
+		}
+
+		queueKey := appctx.QueueKey(guildID, request.VoiceChannelID)
+
+		nowPlaying, err := store.GetNowPlaying(queueKey)
+		if err != nil {
+			httpx.RespondError(write, http.StatusInternalServerError, "Failed to read now playing state")
+			return
+		}
+
+		if (request.From == 0 || request.To == 0) && nowPlaying != nil {
+			httpx.RespondError(write, http.StatusBadRequest, "Cannot move currently playing track.")
+			return
+		}
+
+		from, to := request.From, request.To
+		if nowPlaying != nil {
+			from, to = request.From-1, request.To-1
+		}
+
+		if from < 0 || to < 0 {
+			httpx.RespondError(write, http.StatusBadRequest, "Invalid position")
+			return
+		}
+
+		if err := store.Move(queueKey, from, to); err != nil {
+			if errors.Is(err, appctx.ErrInvalidPosition) {
+				httpx.RespondError(write, http.StatusBadRequest, "Invalid position")
+				return
+			}
+			httpx.RespondError(write, http.StatusInternalServerError, "Failed to move track")
+			return
+		}
+
+		httpx.RespondJSON(write, http.StatusOK, map[string]any{"ok": true})
+	}
+}
+
 func QueueClear() http.HandlerFunc {
 	return func(write http.ResponseWriter, read *http.Request) {
 		type clearRequest struct {
@@ -428,11 +523,7 @@ func QueueClear() http.HandlerFunc {
 			return
 		}
 
-		guildID, errMsg := getGuildID()
-		if errMsg != "" {
-			httpx.RespondError(write, http.StatusInternalServerError, errMsg)
-			return
-		}
+		guildID := guildFromRequest(read)
 
 		store := appctx.GetQueueStore()
 		if store == nil {
@@ -459,182 +550,241 @@ func QueueClear() http.HandlerFunc {
 	}
 }
 
-func QueuePause() http.HandlerFunc {
+func QueueShuffle() http.HandlerFunc {
 	return func(write http.ResponseWriter, read *http.Request) {
-		if discordSessionProvider == nil {
-			httpx.RespondError(write, http.StatusInternalServerError, "Discord session not initialized")
-			return
+		type shuffleRequest struct {
+			VoiceChannelID string `json:"voice_channel_id"`
 		}
 
-		s, _ := discordSessionProvider().(*discordgo.Session)
-		if s == nil {
-			httpx.RespondError(write, http.StatusInternalServerError, "Discord session unavailable")
+		var request shuffleRequest
+		if err := httpx.DecodeJSON(write, read, &request, 1<<20); err != nil {
+			httpx.RespondError(write, http.StatusBadRequest, err.Error())
 			return
 		}
 
-		guildID, errMsg := getGuildID()
-		if errMsg != "" {
-			httpx.RespondError(write, http.StatusInternalServerError, errMsg)
+		if request.VoiceChannelID == "" {
+			httpx.RespondError(write, http.StatusBadRequest, "Missing voice_channel_id")
 			return
 		}
 
-		// Get voice channel ID from request body
-		type pauseRequest struct {
-			VoiceChannelID string `json:"voice_channel_id"`
-		}
-		var req pauseRequest
-		if err := httpx.DecodeJSON(write, read, &req, 1<<20); err != nil {
-			httpx.RespondError(write, http.StatusBadRequest, err.Error())
+		guildID := guildFromRequest(read)
+
+		store := appctx.GetQueueStore()
+		if store == nil {
+			httpx.RespondError(write, http.StatusInternalServerError, "Queue store unavailable")
 			return
 		}
 
-		ctx := &appctx.Context{
-			SourceType:     appctx.SourceTypeWeb,
-			Session:        s,
-			GuildID:        guildID,
-			VoiceChannelID: req.VoiceChannelID,
+		queueKey := appctx.QueueKey(guildID, request.VoiceChannelID)
+		if err := store.Shuffle(queueKey); err != nil {
+			logging.FromContext(read.Context()).Error("Failed to shuffle queue", "error", err)
+			httpx.RespondError(write, http.StatusInternalServerError, "Failed to shuffle queue")
+			return
 		}
 
-		music.PauseSong(ctx)
-
 		httpx.RespondJSON(write, http.StatusOK, map[string]any{"ok": true})
 	}
 }
 
-func QueuePlay() http.HandlerFunc {
+func QueueLoop() http.HandlerFunc {
 	return func(write http.ResponseWriter, read *http.Request) {
-		type playRequest struct {
+		type loopRequest struct {
 			VoiceChannelID string `json:"voice_channel_id"`
+			Mode           string `json:"mode"`
 		}
 
-		var request playRequest
+		var request loopRequest
 		if err := httpx.DecodeJSON(write, read, &request, 1<<20); err != nil {
 			httpx.RespondError(write, http.StatusBadRequest, err.Error())
 			return
 		}
 
-		if discordSessionProvider == nil {
-			httpx.RespondError(write, http.StatusInternalServerError, "Discord session not initialized")
+		if request.VoiceChannelID == "" {
+			httpx.RespondError(write, http.StatusBadRequest, "Missing voice_channel_id")
 			return
 		}
 
-		s, _ := discordSessionProvider().(*discordgo.Session)
-		if s == nil {
-			httpx.RespondError(write, http.StatusInternalServerError, "Discord session unavailable")
+		mode, ok := appctx.ParseLoopMode(request.Mode)
+		if !ok {
+			httpx.RespondError(write, http.StatusBadRequest, "Invalid mode: must be off, track or queue")
 			return
 		}
 
-		guildID, errMsg := getGuildID()
-		if errMsg != "" {
-			httpx.RespondError(write, http.StatusInternalServerError, errMsg)
+		guildID := guildFromRequest(read)
+
+		store := appctx.GetQueueStore()
+		if store == nil {
+			httpx.RespondError(write, http.StatusInternalServerError, "Queue store unavailable")
 			return
 		}
 
-		ctx := &appctx.Context{
-			SourceType:     appctx.SourceTypeWeb,
-			Session:        s,
-			GuildID:        guildID,
-			VoiceChannelID: request.VoiceChannelID,
+		queueKey := appctx.QueueKey(guildID, request.VoiceChannelID)
+		if err := store.SetLoopMode(queueKey, mode); err != nil {
+			logging.FromContext(read.Context()).Error("Failed to set loop mode", "error", err)
+			httpx.RespondError(write, http.StatusInternalServerError, "Failed to set loop mode")
+			return
 		}
 
-		queueKey := appctx.QueueKey(guildID, request.VoiceChannelID)
+		httpx.RespondJSON(write, http.StatusOK, map[string]any{"ok": true, "loop_mode": string(mode)})
+	}
+}
 
-		// Check if paused, if so unpause
-		appctx.PauseMutex.Lock()
-		isPaused := appctx.Paused[queueKey]
-		appctx.PauseMutex.Unlock()
+func QueuePause() http.HandlerFunc {
+	return func(write http.ResponseWriter, read *http.Request) {
+		guildID := guildFromRequest(read)
 
-		if isPaused {
-			music.PauseSong(ctx) // Toggle pause off
-		} else {
-			// Start playing if not already
-			music.ProcessQueue(ctx)
+		type pauseRequest struct {
+			VoiceChannelID string `json:"voice_channel_id"`
 		}
 
-		httpx.RespondJSON(write, http.StatusOK, map[string]any{"ok": true})
+		var req pauseRequest
+		if err := httpx.DecodeJSON(write, read, &req, 1<<20); err != nil {
+			httpx.RespondError(write, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		sendCommand(write, read, http.StatusOK, bus.Command{
+			Type:           bus.CommandPause,
+			GuildID:        guildID,
+			VoiceChannelID: req.VoiceChannelID,
+		}, nil)
 	}
 }
 
-func QueueSkip() http.HandlerFunc {
+func QueuePlay() http.HandlerFunc {
 	return func(write http.ResponseWriter, read *http.Request) {
-		if discordSessionProvider == nil {
-			httpx.RespondError(write, http.StatusInternalServerError, "Discord session not initialized")
-			return
-		}
+		guildID := guildFromRequest(read)
 
-		s, _ := discordSessionProvider().(*discordgo.Session)
-		if s == nil {
-			httpx.RespondError(write, http.StatusInternalServerError, "Discord session unavailable")
-			return
+		type playRequest struct {
+			VoiceChannelID string `json:"voice_channel_id"`
 		}
 
-		guildID, errMsg := getGuildID()
-		if errMsg != "" {
-			httpx.RespondError(write, http.StatusInternalServerError, errMsg)
+		var req playRequest
+		if err := httpx.DecodeJSON(write, read, &req, 1<<20); err != nil {
+			httpx.RespondError(write, http.StatusBadRequest, err.Error())
 			return
 		}
 
-		// Get voice channel ID from request body
+		sendCommand(write, read, http.StatusOK, bus.Command{
+			Type:           bus.CommandPlay,
+			GuildID:        guildID,
+			VoiceChannelID: req.VoiceChannelID,
+		}, nil)
+	}
+}
+
+func QueueSkip() http.HandlerFunc {
+	return func(write http.ResponseWriter, read *http.Request) {
+		guildID := guildFromRequest(read)
+
 		type skipRequest struct {
 			VoiceChannelID string `json:"voice_channel_id"`
 		}
+
 		var req skipRequest
 		if err := httpx.DecodeJSON(write, read, &req, 1<<20); err != nil {
 			httpx.RespondError(write, http.StatusBadRequest, err.Error())
 			return
 		}
 
-		ctx := &appctx.Context{
-			SourceType:     appctx.SourceTypeWeb,
-			Session:        s,
+		sendCommand(write, read, http.StatusOK, bus.Command{
+			Type:           bus.CommandSkip,
 			GuildID:        guildID,
 			VoiceChannelID: req.VoiceChannelID,
+		}, nil)
+	}
+}
+
+func QueueStop() http.HandlerFunc {
+	return func(write http.ResponseWriter, read *http.Request) {
+		guildID := guildFromRequest(read)
+
+		type stopRequest struct {
+			VoiceChannelID string `json:"voice_channel_id"`
 		}
 
-		music.SkipSong(ctx)
+		var req stopRequest
+		if err := httpx.DecodeJSON(write, read, &req, 1<<20); err != nil {
+			httpx.RespondError(write, http.StatusBadRequest, err.Error())
+			return
+		}
 
-		httpx.RespondJSON(write, http.StatusOK, map[string]any{"ok": true})
+		sendCommand(write, read, http.StatusOK, bus.Command{
+			Type:           bus.CommandStop,
+			GuildID:        guildID,
+			VoiceChannelID: req.VoiceChannelID,
+		}, nil)
 	}
 }
 
-func QueueStop() http.HandlerFunc {
+func QueueSeek() http.HandlerFunc {
 	return func(write http.ResponseWriter, read *http.Request) {
-		if discordSessionProvider == nil {
-			httpx.RespondError(write, http.StatusInternalServerError, "Discord session not initialized")
-			return
+		guildID := guildFromRequest(read)
+
+		type seekRequest struct {
+			VoiceChannelID string `json:"voice_channel_id"`
+			PositionMS     int64  `json:"position_ms"`
 		}
 
-		s, _ := discordSessionProvider().(*discordgo.Session)
-		if s == nil {
-			httpx.RespondError(write, http.StatusInternalServerError, "Discord session unavailable")
+		var req seekRequest
+		if err := httpx.DecodeJSON(write, read, &req, 1<<20); err != nil {
+			httpx.RespondError(write, http.StatusBadRequest, err.Error())
 			return
 		}
 
-		guildID, errMsg := getGuildID()
-		if errMsg != "" {
-			httpx.RespondError(write, http.StatusInternalServerError, errMsg)
+		if req.PositionMS < 0 {
+			httpx.RespondError(write, http.StatusBadRequest, "Invalid position_ms")
 			return
 		}
 
-		type stopRequest struct {
-			VoiceChannelID string `json:"voice_channel_id"`
+		sendCommand(write, read, http.StatusOK, bus.Command{
+			Type:           bus.CommandSeek,
+			GuildID:        guildID,
+			VoiceChannelID: req.VoiceChannelID,
+			PositionMS:     req.PositionMS,
+		}, nil)
+	}
+}
+
+func QueueVolume() http.HandlerFunc {
+	return func(write http.ResponseWriter, read *http.Request) {
+		guildID := guildFromRequest(read)
+
+		type volumeRequest struct {
+			VoiceChannelID string  `json:"voice_channel_id"`
+			Level          float64 `json:"level"`
 		}
 
-		var req stopRequest
+		var req volumeRequest
 		if err := httpx.DecodeJSON(write, read, &req, 1<<20); err != nil {
 			httpx.RespondError(write, http.StatusBadRequest, err.Error())
 			return
 		}
 
-		ctx := &appctx.Context{
-			SourceType:     appctx.SourceTypeWeb,
-			Session:        s,
+		if req.Level < 0 || req.Level > 200 {
+			httpx.RespondError(write, http.StatusBadRequest, "Volume must be between 0 and 200")
+			return
+		}
+
+		sendCommand(write, read, http.StatusOK, bus.Command{
+			Type:           bus.CommandVolume,
 			GuildID:        guildID,
 			VoiceChannelID: req.VoiceChannelID,
-		}
+			Volume:         req.Level,
+		}, nil)
+	}
+}
 
-		music.StopSong(ctx)
+// fallbackVoiceChannel picks a voice channel for a web play without one:
+// the requester's current channel, otherwise the guild's default_vc
+func fallbackVoiceChannel(session *discordgo.Session, guildID, userID string) string {
+	if channelID, _, err := getCachedVoiceChannel(session, guildID, userID); err == nil && channelID != "" {
+		return channelID
+	}
 
-		httpx.RespondJSON(write, http.StatusOK, map[string]any{"ok": true})
+	if settings := music.GuildPlaybackSettings(guildID); settings != nil && settings.DefaultVC != nil {
+		return *settings.DefaultVC
 	}
+
+	return ""
 }
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ekkolyth/ekko-bot/internal/api/httpx"
+	appctx "github.com/ekkolyth/ekko-bot/internal/context"
+)
+
+// keeps proxies from closing an idle stream
+const eventsHeartbeat = 15 * time.Second
+
+// QueueEvents streams queue changes for a voice channel as Server-Sent Events
+func QueueEvents() http.HandlerFunc {
+	return func(write http.ResponseWriter, read *http.Request) {
+		voiceChannelID := read.URL.Query().Get("voice_channel_id")
+		if voiceChannelID == "" {
+			httpx.RespondError(write, http.StatusBadRequest, "Missing voice_channel_id query parameter")
+			return
+		}
+
+		guildID := guildFromRequest(read)
+
+		store := appctx.GetQueueStore()
+		if store == nil {
+			httpx.RespondError(write, http.StatusInternalServerError, "Queue store unavailable")
+			return
+		}
+
+		events, err := store.Subscribe(read.Context(), appctx.QueueKey(guildID, voiceChannelID))
+		if err != nil {
+			httpx.RespondError(write, http.StatusInternalServerError, "Failed to subscribe to queue events")
+			return
+		}
+
+		// the server write timeout would otherwise cut the stream
+		controller := http.NewResponseController(write)
+		_ = controller.SetWriteDeadline(time.Time{})
+
+		write.Header().Set("Content-Type", "text/event-stream")
+		write.Header().Set("Cache-Control", "no-cache")
+		write.Header().Set("Connection", "keep-alive")
+		write.Header().Set("X-Accel-Buffering", "no")
+		write.WriteHeader(http.StatusOK)
+
+		fmt.Fprint(write, "retry: 3000\n\n")
+		if err := controller.Flush(); err != nil {
+			return
+		}
+
+		heartbeat := time.NewTicker(eventsHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-read.Context().Done():
+				return
+			case <-heartbeat.C:
+				fmt.Fprint(write, ": ping\n\n")
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				payload, err := json.Marshal(event)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(write, "event: %s\ndata: %s\n\n", event.Type, payload)
+			}
+
+			if err := controller.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ekkolyth/ekko-bot/internal/api/httpx"
+	"github.com/ekkolyth/ekko-bot/internal/config"
+	"github.com/ekkolyth/ekko-bot/internal/logging"
+	"github.com/ekkolyth/ekko-bot/internal/ratelimit"
+)
+
+var rateLimiter *ratelimit.Limiter // set via SetRateLimiter
+
+// SetRateLimiter wires the limiter shared by the API replicas
+func SetRateLimiter(limiter *ratelimit.Limiter) {
+	rateLimiter = limiter
+}
+
+// RateLimit counts each request against the caller's read or write budget and
+// answers 429 once it is spent. Callers are keyed by user when they send a
+// valid token, otherwise by client IP.
+func RateLimit() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(write http.ResponseWriter, read *http.Request) {
+			if rateLimiter == nil {
+				next.ServeHTTP(write, read)
+				return
+			}
+
+			readBudget, writeBudget := config.RateLimits()
+			budget := writeBudget
+			switch read.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				budget = readBudget
+			}
+
+			result, err := rateLimiter.Take(read.Context(), budget, rateLimitKey(read))
+			if err != nil {
+				// fail open, a redis hiccup shouldn't take the dashboard down
+				logging.FromContext(read.Context()).Warn("Rate limit check failed", "error", err)
+				next.ServeHTTP(write, read)
+				return
+			}
+
+			write.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			write.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			write.Header().Set("RateLimit-Reset", headerSeconds(result.Reset))
+
+			if !result.Allowed {
+				write.Header().Set("Retry-After", headerSeconds(result.RetryAfter))
+				httpx.RespondError(write, http.StatusTooManyRequests, "Rate limit exceeded, retry in "+headerSeconds(result.RetryAfter)+"s")
+				return
+			}
+
+			next.ServeHTTP(write, read)
+		})
+	}
+}
+
+// return the bucket owner for the request. This runs before Authenticate so
+// requests with bad tokens are limited too; a token with a valid signature is
+// enough to key by user.
+func rateLimitKey(read *http.Request) string {
+	if secret := config.APIAuthSecret(); secret != "" {
+		if claims, err := httpx.VerifyToken([]byte(secret), bearerToken(read, true), time.Now()); err == nil {
+			return "user:" + claims.Subject
+		}
+	}
+
+	// RealIP has already replaced RemoteAddr with the forwarded client address
+	host, _, err := net.SplitHostPort(read.RemoteAddr)
+	if err != nil {
+		host = read.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// format a duration as whole seconds, rounded up
+func headerSeconds(duration time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(duration.Seconds())))
+}
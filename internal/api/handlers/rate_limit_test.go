@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ekkolyth/ekko-bot/internal/ratelimit"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRateLimitSeparatesReadAndWriteBudgets(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	SetRateLimiter(ratelimit.NewLimiter(client))
+	t.Cleanup(func() { SetRateLimiter(nil) })
+	t.Setenv("API_AUTH_SECRET", "")
+	t.Setenv("RATE_LIMIT_READ_BURST", "2")
+	t.Setenv("RATE_LIMIT_WRITE_BURST", "1")
+
+	handler := RateLimit()(http.HandlerFunc(func(write http.ResponseWriter, _ *http.Request) {
+		write.WriteHeader(http.StatusNoContent)
+	}))
+	send := func(method string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, "/api/queue", nil)
+		request.RemoteAddr = "203.0.113.7:51234"
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	if got := send(http.MethodPost).Code; got != http.StatusNoContent {
+		t.Fatalf("first POST = %d; want %d", got, http.StatusNoContent)
+	}
+	denied := send(http.MethodDelete)
+	if denied.Code != http.StatusTooManyRequests {
+		t.Fatalf("DELETE past the write burst = %d; want %d", denied.Code, http.StatusTooManyRequests)
+	}
+	if denied.Header().Get("Retry-After") == "" {
+		t.Error("429 without Retry-After")
+	}
+
+	// reads spend their own budget, GET and HEAD alike
+	if got := send(http.MethodGet); got.Code != http.StatusNoContent || got.Header().Get("RateLimit-Limit") != "2" {
+		t.Fatalf("GET after writes ran out = %d, limit %q; want %d, limit 2", got.Code, got.Header().Get("RateLimit-Limit"), http.StatusNoContent)
+	}
+	if got := send(http.MethodHead).Code; got != http.StatusNoContent {
+		t.Fatalf("HEAD = %d; want %d", got, http.StatusNoContent)
+	}
+	if got := send(http.MethodGet).Code; got != http.StatusTooManyRequests {
+		t.Errorf("GET past the read burst = %d; want %d", got, http.StatusTooManyRequests)
+	}
+}
@@ -21,14 +21,16 @@ type voiceChannelDTO struct {
 
 func VoiceChannelCurrent() http.HandlerFunc {
 	return func(write http.ResponseWriter, read *http.Request) {
-		userID := read.URL.Query().Get("discord_user_id")
-		if userID == "" {
-			httpx.RespondJSON(write, http.StatusBadRequest, voiceChannelResponse{
+		// the channel is always looked up for the authenticated caller
+		caller := callerFromRequest(read)
+		if caller == nil {
+			httpx.RespondJSON(write, http.StatusUnauthorized, voiceChannelResponse{
 				OK:    false,
-				Error: "Missing discord_user_id",
+				Error: "Missing caller identity",
 			})
 			return
 		}
+		userID := caller.DiscordUserID
 
 		if discordSessionProvider == nil {
 			httpx.RespondJSON(write, http.StatusInternalServerError, voiceChannelResponse{
@@ -47,14 +49,7 @@ func VoiceChannelCurrent() http.HandlerFunc {
 			return
 		}
 
-		guildID, errMsg := getGuildID()
-		if errMsg != "" {
-			httpx.RespondJSON(write, http.StatusInternalServerError, voiceChannelResponse{
-				OK:    false,
-				Error: errMsg,
-			})
-			return
-		}
+		guildID := guildFromRequest(read)
 
 		channelID, channelName, err := getCachedVoiceChannel(session, guildID, userID)
 		if err != nil {
@@ -23,11 +23,7 @@ type welcomeConfigRequest struct {
 
 func WelcomeConfigGet(service *appdb.GuildConfigService) http.HandlerFunc {
 	return func(write http.ResponseWriter, read *http.Request) {
-		guildID, errMsg := getGuildID()
-		if errMsg != "" {
-			httpx.RespondError(write, http.StatusInternalServerError, errMsg)
-			return
-		}
+		guildID := guildFromRequest(read)
 
 		if service == nil {
 			httpx.RespondError(write, http.StatusInternalServerError, "Welcome config unavailable")
@@ -55,11 +51,7 @@ func WelcomeConfigGet(service *appdb.GuildConfigService) http.HandlerFunc {
 
 func WelcomeConfigSave(service *appdb.GuildConfigService) http.HandlerFunc {
 	return func(write http.ResponseWriter, read *http.Request) {
-		guildID, errMsg := getGuildID()
-		if errMsg != "" {
-			httpx.RespondError(write, http.StatusInternalServerError, errMsg)
-			return
-		}
+		guildID := guildFromRequest(read)
 
 		if service == nil {
 			httpx.RespondError(write, http.StatusInternalServerError, "Welcome config unavailable")
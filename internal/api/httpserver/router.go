@@ -12,6 +12,7 @@ import (
 
 	"github.com/ekkolyth/ekko-bot/internal/api/handlers"
 	"github.com/ekkolyth/ekko-bot/internal/db"
+	"github.com/ekkolyth/ekko-bot/internal/metrics"
 )
 
 func NewRouter(dbService *db.Service) http.Handler {
@@ -19,9 +20,10 @@ func NewRouter(dbService *db.Service) http.Handler {
 
 	// standard middleware
 	router.Use(middleware.RequestID)
+	router.Use(handlers.LogContext())
 	router.Use(middleware.RealIP)
 	router.Use(middleware.Recoverer)
-	router.Use(middleware.Timeout(15 * time.Second))
+	router.Use(metrics.Middleware)
 
 	allowedOrigins := envList("CORS_ALLOWED_ORIGINS")
 
@@ -48,40 +50,84 @@ func NewRouter(dbService *db.Service) http.Handler {
 		MaxAge:           1800, // 1 Hour
 	}))
 
-	// Healthcheck
-	router.Get("/api/healthz", handlers.Health)
-
-	router.Route("/api", func(api chi.Router) {
-		api.Get("/voice-channel", handlers.VoiceChannelCurrent())
-
-		api.Route("/queue", func(queue chi.Router) {
-			queue.Get("/", handlers.QueueGet())
-			queue.Get("/recent", handlers.QueueRecent())
-			queue.Post("/", handlers.QueueAdd())
-			queue.Post("/remove", handlers.QueueRemove())
-			queue.Post("/clear", handlers.QueueClear())
-			queue.Post("/pause", handlers.QueuePause())
-			queue.Post("/play", handlers.QueuePlay())
-			queue.Post("/skip", handlers.QueueSkip())
-			queue.Post("/stop", handlers.QueueStop())
-		})
+	authenticate := handlers.Authenticate(dbService.DiscordAccounts)
+	authenticateStream := handlers.AuthenticateStream(dbService.DiscordAccounts)
+	rateLimit := handlers.RateLimit()
 
-		api.Route("/commands", func(commands chi.Router) {
-			commands.Get("/", handlers.CommandsList(dbService.CustomCommands))
-			commands.Post("/", handlers.CommandsCreate(dbService.CustomCommands))
-			commands.Patch("/{id}", handlers.CommandsUpdate(dbService.CustomCommands))
-			commands.Delete("/{id}", handlers.CommandsDelete(dbService.CustomCommands))
-		})
+	// event streams stay open, so they sit outside the request timeout
+	router.With(rateLimit, authenticateStream, handlers.RequireGuild()).Get("/api/guilds/{guildID}/queue/events", handlers.QueueEvents())
+	router.With(rateLimit, authenticateStream, handlers.DefaultGuild()).Get("/api/queue/events", handlers.QueueEvents())
+
+	router.Group(func(timed chi.Router) {
+		timed.Use(middleware.Timeout(15 * time.Second))
+
+		// Healthcheck
+		timed.Get("/api/healthz", handlers.Health)
+		timed.Get("/api/readyz", handlers.Ready)
+
+		// links the token's Discord account, before Authenticate can look it up
+		timed.With(rateLimit).Post("/api/auth/link", handlers.LinkAccount(dbService.DiscordAccounts))
 
-		api.Route("/welcome-config", func(welcome chi.Router) {
-			welcome.Get("/", handlers.WelcomeConfigGet(dbService.GuildConfig))
-			welcome.Put("/", handlers.WelcomeConfigSave(dbService.GuildConfig))
+		timed.Route("/api", func(api chi.Router) {
+			api.Use(rateLimit, authenticate)
+
+			api.Route("/guilds/{guildID}", func(guild chi.Router) {
+				guild.Use(handlers.RequireGuild())
+				guildRoutes(guild, dbService)
+			})
+
+			// unscoped routes for single guild setups, resolved from DISCORD_GUILD_ID
+			api.Group(func(guild chi.Router) {
+				guild.Use(handlers.DefaultGuild())
+				guildRoutes(guild, dbService)
+			})
 		})
 	})
 
 	return router
 }
 
+// register the routes scoped to a single guild, writes need Manage Server or the DJ role
+func guildRoutes(guild chi.Router, dbService *db.Service) {
+	guild.Use(handlers.RequireWritePermission())
+
+	guild.Get("/voice-channel", handlers.VoiceChannelCurrent())
+
+	guild.Route("/queue", func(queue chi.Router) {
+		queue.Get("/", handlers.QueueGet())
+		queue.Get("/recent", handlers.QueueRecent())
+		queue.Post("/", handlers.QueueAdd())
+		queue.Post("/remove", handlers.QueueRemove())
+		queue.Post("/move", handlers.QueueMove())
+		queue.Post("/clear", handlers.QueueClear())
+		queue.Post("/shuffle", handlers.QueueShuffle())
+		queue.Post("/loop", handlers.QueueLoop())
+		queue.Post("/pause", handlers.QueuePause())
+		queue.Post("/play", handlers.QueuePlay())
+		queue.Post("/skip", handlers.QueueSkip())
+		queue.Post("/stop", handlers.QueueStop())
+		queue.Post("/seek", handlers.QueueSeek())
+		queue.Post("/volume", handlers.QueueVolume())
+	})
+
+	guild.Route("/commands", func(commands chi.Router) {
+		commands.Get("/", handlers.CommandsList(dbService.CustomCommands))
+		commands.Post("/", handlers.CommandsCreate(dbService.CustomCommands))
+		commands.Patch("/{id}", handlers.CommandsUpdate(dbService.CustomCommands))
+		commands.Delete("/{id}", handlers.CommandsDelete(dbService.CustomCommands))
+	})
+
+	guild.Route("/welcome-config", func(welcome chi.Router) {
+		welcome.Get("/", handlers.WelcomeConfigGet(dbService.GuildConfig))
+		welcome.Put("/", handlers.WelcomeConfigSave(dbService.GuildConfig))
+	})
+
+	guild.Route("/guild-config", func(guildConfig chi.Router) {
+		guildConfig.Get("/", handlers.GuildConfigGet(dbService.GuildConfig))
+		guildConfig.Put("/", handlers.GuildConfigSave(dbService.GuildConfig))
+	})
+}
+
 func envList(key string) []string {
 	v := os.Getenv(key)
 	if v == "" {
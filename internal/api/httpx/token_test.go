@@ -0,0 +1,48 @@
+package httpx
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func signTestToken(secret, header, payload string) string {
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(header)) + "." + base64.RawURLEncoding.EncodeToString([]byte(payload))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(encoded))
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyToken(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	header := `{"alg":"HS256","typ":"JWT"}`
+	valid := `{"sub":"user-1","discord_user_id":"123","iat":1700000000,"exp":1700000060}`
+
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"valid", signTestToken("secret", header, valid), nil},
+		{"wrong secret", signTestToken("other", header, valid), ErrTokenSignature},
+		{"expired", signTestToken("secret", header, `{"sub":"user-1","exp":1699999900}`), ErrTokenExpired},
+		{"within leeway", signTestToken("secret", header, `{"sub":"user-1","exp":1699999990}`), nil},
+		{"missing subject", signTestToken("secret", header, `{"exp":1700000060}`), ErrTokenMalformed},
+		{"alg none", signTestToken("secret", `{"alg":"none"}`, valid), ErrTokenMalformed},
+		{"not a jwt", "abc", ErrTokenMalformed},
+	}
+
+	for _, tt := range tests {
+		claims, err := VerifyToken([]byte("secret"), tt.token, now)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.err, err)
+			continue
+		}
+		if err == nil && claims.Subject != "user-1" {
+			t.Errorf("%s: expected subject user-1, got %q", tt.name, claims.Subject)
+		}
+	}
+}
@@ -6,15 +6,9 @@ import (
 	"time"
 
 	"github.com/ekkolyth/ekko-bot/internal/context"
-
-	"github.com/bwmarrin/discordgo"
 )
 
 func Uptime(ctx *context.Context) {
-	if !context.HasPermission(ctx, discordgo.PermissionAdministrator) {
-		ctx.Reply("You do not have permission to use this command.")
-		return
-	}
 	timeNow := time.Now()
 	uptime := timeNow.Sub(context.StartTime)
 	// convert to days, hours, minutes, seconds
@@ -2,27 +2,32 @@ package httpx
 
 import (
 	"github.com/ekkolyth/ekko-bot/internal/lua"
+	"github.com/ekkolyth/ekko-bot/internal/provider"
 	luaLib "github.com/yuin/gopher-lua"
 )
 
-// IsValidURL validates a URL using embedded Lua scripts
-// Returns true only for valid YouTube URLs (shorts are rejected)
+// IsValidURL reports whether a registered media provider can play input
 func IsValidURL(input string) bool {
+	_, ok := provider.Lookup(input)
+	return ok
+}
+
+// NormalizePlaylistURL returns the canonical YouTube playlist URL for input
+// The second return value is false when input is not a playlist URL
+func NormalizePlaylistURL(input string) (string, bool) {
 	script := lua.Get()
-	if err := script.LoadScript("lua/scripts/validate_url/validate_url.lua"); err != nil {
-		return false
+	if err := script.LoadScript("lua/scripts/validate_url/validate_youtube_url.lua"); err != nil {
+		return "", false
 	}
 
-	results, err := script.CallLuaFunc("validate_url", "validate_url", 3, luaLib.LString(input))
+	results, err := script.CallLuaFunc("validate_youtube_url", "normalize_youtube_playlist_url", 2, luaLib.LString(input))
 	if err != nil {
-		return false
+		return "", false
 	}
 
-	ok := results[0] != luaLib.LFalse && results[0] != luaLib.LNil
-	provider := ""
-	if results[1].Type() != luaLib.LTNil {
-		provider = results[1].String()
+	if results[1].Type() != luaLib.LTNil || results[0].Type() == luaLib.LTNil {
+		return "", false
 	}
 
-	return ok && provider == "youtube"
+	return results[0].String(), true
 }
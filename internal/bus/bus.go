@@ -0,0 +1,85 @@
+package bus
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// CommandType identifies a playback command sent from the API to the bot
+type CommandType string
+
+const (
+	CommandEnqueue CommandType = "enqueue" // queue URL and start playback
+	CommandPlay    CommandType = "play"    // resume if paused, otherwise start the queue
+	CommandPause   CommandType = "pause"   // toggle pause
+	CommandSkip    CommandType = "skip"
+	CommandStop    CommandType = "stop"
+	CommandVolume  CommandType = "volume" // set Volume (percent)
+	CommandSeek    CommandType = "seek"   // restart the current track at PositionMS
+)
+
+// Command is a request for the bot process to act on one of its players
+type Command struct {
+	ID             string      `json:"id"`
+	Type           CommandType `json:"type"`
+	GuildID        string      `json:"guild_id"`
+	VoiceChannelID string      `json:"voice_channel_id"`
+	UserID         string      `json:"user_id,omitempty"`
+	UserTag        string      `json:"user_tag,omitempty"`
+	URL            string      `json:"url,omitempty"`
+	Volume         float64     `json:"volume,omitempty"`
+	PositionMS     int64       `json:"position_ms,omitempty"`
+	RequestID      string      `json:"request_id,omitempty"` // API request that sent the command, for log correlation
+	SentAt         int64       `json:"sent_at"`              // unix milliseconds
+}
+
+// Reply acknowledges a command, Error is set when it failed
+type Reply struct {
+	ID       string   `json:"id"`
+	OK       bool     `json:"ok"`
+	Error    string   `json:"error,omitempty"`
+	Messages []string `json:"messages,omitempty"` // what the bot would have said in Discord
+}
+
+const (
+	// ReplyTimeout is how long the API waits for the bot, kept below the HTTP write timeout
+	ReplyTimeout = 8 * time.Second
+	// CommandTTL is how long a command stays valid. The bot drops older
+	// commands because the API has stopped waiting for them.
+	CommandTTL = 10 * time.Second
+)
+
+var (
+	// ErrNoReply indicates the bot did not answer in time
+	ErrNoReply = errors.New("bot did not reply")
+	// ErrExpired indicates the command was too old to run
+	ErrExpired = errors.New("command expired")
+)
+
+// CommandError is a failure reported by the bot
+type CommandError struct {
+	Message string
+}
+
+func (e *CommandError) Error() string {
+	return e.Message
+}
+
+// return the list the bot consumes commands from
+func commandsKey() string {
+	return "bus:commands"
+}
+
+// return the list a command's reply is pushed to
+func replyKey(id string) string {
+	return "bus:reply:" + id
+}
+
+// return a random command id
+func newID() string {
+	buf := make([]byte, 12)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
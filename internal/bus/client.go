@@ -0,0 +1,60 @@
+package bus
+
+import (
+	stdctx "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Client sends commands to the bot process
+type Client struct {
+	redis *redis.Client
+}
+
+// NewClient returns a client publishing over redis
+func NewClient(client *redis.Client) *Client {
+	return &Client{redis: client}
+}
+
+// Send publishes cmd and waits for the bot's reply. Returns a *CommandError
+// when the bot reports a failure and ErrNoReply when it does not answer.
+func (c *Client) Send(ctx stdctx.Context, cmd Command) (*Reply, error) {
+	cmd.ID = newID()
+	cmd.SentAt = time.Now().UnixMilli()
+
+	payload, err := json.Marshal(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.redis.RPush(ctx, commandsKey(), payload).Err(); err != nil {
+		return nil, fmt.Errorf("publish %s: %w", cmd.Type, err)
+	}
+
+	timeout := ReplyTimeout
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+		timeout = time.Until(deadline)
+	}
+
+	result, err := c.redis.BLPop(ctx, timeout, replyKey(cmd.ID)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) || errors.Is(err, stdctx.DeadlineExceeded) {
+			return nil, ErrNoReply
+		}
+		return nil, fmt.Errorf("await %s reply: %w", cmd.Type, err)
+	}
+
+	var reply Reply
+	if err := json.Unmarshal([]byte(result[1]), &reply); err != nil {
+		return nil, fmt.Errorf("decode %s reply: %w", cmd.Type, err)
+	}
+
+	if !reply.OK {
+		return &reply, &CommandError{Message: reply.Error}
+	}
+	return &reply, nil
+}
@@ -0,0 +1,118 @@
+package bus
+
+import (
+	stdctx "context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ekkolyth/ekko-bot/internal/logging"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Handler executes a command and returns the messages to include in the reply
+type Handler func(cmd Command) ([]string, error)
+
+// Serve consumes commands until ctx is cancelled and replies to each.
+// Commands for one guild run in the order they were sent, different guilds
+// run concurrently so a slow enqueue can't stall everyone else.
+func Serve(ctx stdctx.Context, client *redis.Client, handle Handler) {
+	workers := newGuildWorkers()
+	for ctx.Err() == nil {
+		result, err := client.BLPop(ctx, 5*time.Second, commandsKey()).Result()
+		if err != nil {
+			if errors.Is(err, redis.Nil) || ctx.Err() != nil {
+				continue
+			}
+			logging.Error("Command bus read failed: " + err.Error())
+			time.Sleep(time.Second)
+			continue
+		}
+
+		var cmd Command
+		if err := json.Unmarshal([]byte(result[1]), &cmd); err != nil {
+			logging.Error("Command bus decode failed: " + err.Error())
+			continue
+		}
+
+		workers.dispatch(cmd, func(cmd Command) {
+			if err := publishReply(ctx, client, run(cmd, handle)); err != nil {
+				logging.Error("Command bus reply failed: " + err.Error())
+			}
+		})
+	}
+}
+
+// run one command, expired commands are refused without running
+func run(cmd Command, handle Handler) Reply {
+	reply := Reply{ID: cmd.ID, OK: true}
+	if time.Since(time.UnixMilli(cmd.SentAt)) > CommandTTL {
+		reply.OK = false
+		reply.Error = ErrExpired.Error()
+	} else if messages, handleErr := handle(cmd); handleErr != nil {
+		reply.OK = false
+		reply.Error = handleErr.Error()
+		reply.Messages = messages
+	} else {
+		reply.Messages = messages
+	}
+	return reply
+}
+
+// guildWorkers runs each guild's commands in order on a goroutine of its
+// own, which exits once the guild has nothing pending
+type guildWorkers struct {
+	mu      sync.Mutex
+	pending map[string][]Command // by guild, present while its worker runs
+}
+
+func newGuildWorkers() *guildWorkers {
+	return &guildWorkers{pending: make(map[string][]Command)}
+}
+
+// queue cmd behind the guild's earlier commands, starting a worker if needed
+func (w *guildWorkers) dispatch(cmd Command, execute func(Command)) {
+	w.mu.Lock()
+	queued, running := w.pending[cmd.GuildID]
+	w.pending[cmd.GuildID] = append(queued, cmd)
+	w.mu.Unlock()
+
+	if !running {
+		go w.drain(cmd.GuildID, execute)
+	}
+}
+
+func (w *guildWorkers) drain(guildID string, execute func(Command)) {
+	for {
+		w.mu.Lock()
+		queued := w.pending[guildID]
+		if len(queued) == 0 {
+			delete(w.pending, guildID)
+			w.mu.Unlock()
+			return
+		}
+		cmd := queued[0]
+		w.pending[guildID] = queued[1:]
+		w.mu.Unlock()
+
+		execute(cmd)
+	}
+}
+
+// push the reply where the sender is waiting, it expires if nobody reads it
+func publishReply(ctx stdctx.Context, client *redis.Client, reply Reply) error {
+	payload, err := json.Marshal(reply)
+	if err != nil {
+		return err
+	}
+
+	key := replyKey(reply.ID)
+	_, err = client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.RPush(ctx, key, payload)
+		pipe.Expire(ctx, key, CommandTTL)
+		return nil
+	})
+	return err
+}
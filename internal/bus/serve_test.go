@@ -0,0 +1,50 @@
+package bus
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGuildWorkersKeepOrderAndDontBlockOtherGuilds(t *testing.T) {
+	workers := newGuildWorkers()
+
+	release := make(chan struct{})
+	otherDone := make(chan struct{})
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	execute := func(cmd Command) {
+		defer wg.Done()
+		if cmd.ID == "slow" {
+			<-release
+		}
+		if cmd.GuildID == "other" {
+			close(otherDone)
+		}
+		mu.Lock()
+		order = append(order, cmd.ID)
+		mu.Unlock()
+	}
+
+	wg.Add(3)
+	workers.dispatch(Command{ID: "slow", GuildID: "busy"}, execute)
+	workers.dispatch(Command{ID: "after", GuildID: "busy"}, execute)
+	workers.dispatch(Command{ID: "other", GuildID: "other"}, execute)
+
+	select {
+	case <-otherDone:
+	case <-time.After(time.Second):
+		t.Fatal("a slow command blocked another guild")
+	}
+
+	close(release)
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(order) != 3 || order[0] != "other" || order[1] != "slow" || order[2] != "after" {
+		t.Errorf("order = %v; want [other slow after]", order)
+	}
+}
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	stdctx "context"
+
+	"github.com/ekkolyth/ekko-bot/internal/logging"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// pub/sub channel carrying the app user ids whose Discord account was relinked
+const identityLinkedChannel = "auth:identity:linked"
+
+// PublishIdentityLinked tells every API replica that appUserID was linked to
+// another Discord account, best effort since the link is already stored
+func PublishIdentityLinked(client *redis.Client, appUserID string) {
+	if err := client.Publish(stdctx.Background(), identityLinkedChannel, appUserID).Err(); err != nil {
+		logging.Error("Failed to publish identity link: " + err.Error())
+	}
+}
+
+// SubscribeIdentityLinked calls forget with every relinked app user id until
+// ctx is done, so cached identities don't outlive a relink on another replica
+func SubscribeIdentityLinked(ctx stdctx.Context, client *redis.Client, forget func(appUserID string)) error {
+	pubsub := client.Subscribe(ctx, identityLinkedChannel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return err
+	}
+
+	go func() {
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				forget(message.Payload)
+			}
+		}
+	}()
+	return nil
+}
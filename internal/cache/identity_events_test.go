@@ -0,0 +1,35 @@
+package cache
+
+import (
+	stdctx "context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestIdentityLinkedReachesSubscribers(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	ctx, cancel := stdctx.WithCancel(stdctx.Background())
+	defer cancel()
+
+	forgotten := make(chan string, 1)
+	if err := SubscribeIdentityLinked(ctx, client, func(appUserID string) { forgotten <- appUserID }); err != nil {
+		t.Fatalf("SubscribeIdentityLinked() error = %v", err)
+	}
+
+	PublishIdentityLinked(client, "user_123")
+
+	select {
+	case appUserID := <-forgotten:
+		if appUserID != "user_123" {
+			t.Errorf("forgot %q; want user_123", appUserID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("relink never reached the subscriber")
+	}
+}
@@ -0,0 +1,21 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// APIAuthSecret is the shared secret the web app signs API tokens with
+func APIAuthSecret() string {
+	return strings.TrimSpace(os.Getenv("API_AUTH_SECRET"))
+}
+
+// DJRoleName is the guild role that may control playback without Manage Server
+//
+//	DJ_ROLE_NAME  matched case-insensitively (default DJ)
+func DJRoleName() string {
+	if name := strings.TrimSpace(os.Getenv("DJ_ROLE_NAME")); name != "" {
+		return name
+	}
+	return "DJ"
+}
@@ -0,0 +1,8 @@
+package config
+
+// MaxFileSize is the largest audio file, attached or linked, that may be queued, in bytes
+//
+//	MAX_FILE_SIZE_MB  1-1024 (default 25)
+func MaxFileSize() int64 {
+	return int64(clamp(envInt("MAX_FILE_SIZE_MB", 25), 1, 1024)) << 20
+}
@@ -0,0 +1,20 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// BotHealthAddr is where the bot serves its internal health endpoints
+//
+//	BOT_HEALTH_ADDR  listen address (default :1338), "off" disables the listener
+func BotHealthAddr() string {
+	addr := strings.TrimSpace(os.Getenv("BOT_HEALTH_ADDR"))
+	switch strings.ToLower(addr) {
+	case "":
+		return ":1338"
+	case "off", "false", "0":
+		return ""
+	}
+	return addr
+}
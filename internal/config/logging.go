@@ -0,0 +1,30 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// LogLevel is the lowest level written to the log
+//
+//	LOG_LEVEL  debug, info, warn or error (default info)
+func LogLevel() slog.Level {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("LOG_LEVEL"))) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// LogJSON reports whether log lines are written as JSON instead of text
+//
+//	LOG_FORMAT  text or json (default text)
+func LogJSON() bool {
+	return strings.EqualFold(strings.TrimSpace(os.Getenv("LOG_FORMAT")), "json")
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// BotMetricsAddr is where the bot serves /metrics, empty when disabled
+//
+//	BOT_METRICS_ADDR  listen address (default off), may equal BOT_HEALTH_ADDR to share the listener
+func BotMetricsAddr() string {
+	addr := strings.TrimSpace(os.Getenv("BOT_METRICS_ADDR"))
+	switch strings.ToLower(addr) {
+	case "off", "false", "0":
+		return ""
+	}
+	return addr
+}
+
+// APIMetricsAddr is where the API serves /metrics, empty when disabled. It is a
+// separate listener so the public API port never exposes it.
+//
+//	API_METRICS_ADDR  listen address (default off)
+func APIMetricsAddr() string {
+	addr := strings.TrimSpace(os.Getenv("API_METRICS_ADDR"))
+	switch strings.ToLower(addr) {
+	case "off", "false", "0":
+		return ""
+	}
+	return addr
+}
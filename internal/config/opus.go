@@ -0,0 +1,80 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// OpusSettings controls how PCM is encoded for each voice connection
+type OpusSettings struct {
+	Bitrate     int    // bits per second, 0 keeps the encoder default
+	Application string // "audio", "voip" or "lowdelay"
+	FEC         bool   // in-band forward error correction
+	PacketLoss  int    // expected packet loss percentage, FEC only kicks in above 0
+}
+
+// Opus reads the encoder settings from the environment
+//
+//	OPUS_BITRATE      bits per second (6000-510000)
+//	OPUS_APPLICATION  audio | voip | lowdelay (default audio)
+//	OPUS_FEC          true/false (default false)
+//	OPUS_PACKET_LOSS  0-100 (default 10 when FEC is enabled)
+func Opus() OpusSettings {
+	settings := OpusSettings{
+		Bitrate:     envInt("OPUS_BITRATE", 0),
+		Application: strings.ToLower(strings.TrimSpace(os.Getenv("OPUS_APPLICATION"))),
+		FEC:         envBool("OPUS_FEC", false),
+	}
+
+	if settings.Bitrate != 0 {
+		settings.Bitrate = clamp(settings.Bitrate, 6000, 510000)
+	}
+
+	switch settings.Application {
+	case "audio", "voip", "lowdelay":
+	default:
+		settings.Application = "audio"
+	}
+
+	defaultLoss := 0
+	if settings.FEC {
+		defaultLoss = 10
+	}
+	settings.PacketLoss = clamp(envInt("OPUS_PACKET_LOSS", defaultLoss), 0, 100)
+
+	return settings
+}
+
+// return an int env var or the fallback when unset or invalid
+func envInt(key string, fallback int) int {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fallback
+	}
+	return parsed
+}
+
+// return a bool env var (true/1/yes) or the fallback when unset
+func envBool(key string, fallback bool) bool {
+	value := strings.ToLower(strings.TrimSpace(os.Getenv(key)))
+	if value == "" {
+		return fallback
+	}
+	return value == "true" || value == "1" || value == "yes"
+}
+
+// clamp value to [min, max]
+func clamp(value, min, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
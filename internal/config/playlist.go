@@ -0,0 +1,8 @@
+package config
+
+// PlaylistLimit returns the maximum number of tracks added from one playlist
+//
+//	PLAYLIST_LIMIT  1-500 (default 50)
+func PlaylistLimit() int {
+	return clamp(envInt("PLAYLIST_LIMIT", 50), 1, 500)
+}
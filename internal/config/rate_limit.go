@@ -0,0 +1,29 @@
+package config
+
+// RateBudget is a token bucket: Burst requests at once, refilled at PerMinute
+type RateBudget struct {
+	Name      string
+	Burst     int
+	PerMinute int
+}
+
+// RateLimits reads the API rate limit budgets from the environment. Setting a
+// rate to 0 disables that budget.
+//
+//	RATE_LIMIT_READ_PER_MINUTE   0-6000 (default 120)
+//	RATE_LIMIT_READ_BURST        1-1000 (default 60)
+//	RATE_LIMIT_WRITE_PER_MINUTE  0-600 (default 20)
+//	RATE_LIMIT_WRITE_BURST       1-100 (default 5)
+func RateLimits() (read RateBudget, write RateBudget) {
+	read = RateBudget{
+		Name:      "read",
+		Burst:     clamp(envInt("RATE_LIMIT_READ_BURST", 60), 1, 1000),
+		PerMinute: clamp(envInt("RATE_LIMIT_READ_PER_MINUTE", 120), 0, 6000),
+	}
+	write = RateBudget{
+		Name:      "write",
+		Burst:     clamp(envInt("RATE_LIMIT_WRITE_BURST", 5), 1, 100),
+		PerMinute: clamp(envInt("RATE_LIMIT_WRITE_PER_MINUTE", 20), 0, 600),
+	}
+	return read, write
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// SpotifySettings are the Web API credentials used to read Spotify links
+type SpotifySettings struct {
+	ClientID     string
+	ClientSecret string
+	APIURL       string // Web API base, overridable to point tests at a fake
+	AccountsURL  string // token endpoint base
+}
+
+// Spotify reads the Web API settings from the environment
+//
+//	SPOTIFY_CLIENT_ID      app client ID, Spotify links are rejected without it
+//	SPOTIFY_CLIENT_SECRET  app client secret
+//	SPOTIFY_API_URL        Web API base (default https://api.spotify.com/v1)
+//	SPOTIFY_ACCOUNTS_URL   token endpoint base (default https://accounts.spotify.com)
+func Spotify() SpotifySettings {
+	settings := SpotifySettings{
+		ClientID:     strings.TrimSpace(os.Getenv("SPOTIFY_CLIENT_ID")),
+		ClientSecret: strings.TrimSpace(os.Getenv("SPOTIFY_CLIENT_SECRET")),
+		APIURL:       strings.TrimRight(strings.TrimSpace(os.Getenv("SPOTIFY_API_URL")), "/"),
+		AccountsURL:  strings.TrimRight(strings.TrimSpace(os.Getenv("SPOTIFY_ACCOUNTS_URL")), "/"),
+	}
+	if settings.APIURL == "" {
+		settings.APIURL = "https://api.spotify.com/v1"
+	}
+	if settings.AccountsURL == "" {
+		settings.AccountsURL = "https://accounts.spotify.com"
+	}
+	return settings
+}
+
+// Enabled reports whether both credentials are set
+func (settings SpotifySettings) Enabled() bool {
+	return settings.ClientID != "" && settings.ClientSecret != ""
+}
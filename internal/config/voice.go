@@ -0,0 +1,40 @@
+package config
+
+import "time"
+
+// IdleTimeout is how long the bot stays connected with an empty queue
+//
+//	IDLE_TIMEOUT_MINUTES  0-1440 (default 5), 0 leaves as soon as the queue ends
+func IdleTimeout() time.Duration {
+	return time.Duration(clamp(envInt("IDLE_TIMEOUT_MINUTES", 5), 0, 1440)) * time.Minute
+}
+
+// EmptyChannelTimeout is how long playback stays paused after everyone leaves the voice channel
+//
+//	EMPTY_CHANNEL_TIMEOUT_SECONDS  0-3600 (default 60)
+func EmptyChannelTimeout() time.Duration {
+	return time.Duration(clamp(envInt("EMPTY_CHANNEL_TIMEOUT_SECONDS", 60), 0, 3600)) * time.Second
+}
+
+// ShutdownTimeout is how long the bot waits for players to stop when it is asked to exit
+//
+//	SHUTDOWN_TIMEOUT_SECONDS  1-60 (default 10)
+func ShutdownTimeout() time.Duration {
+	return time.Duration(clamp(envInt("SHUTDOWN_TIMEOUT_SECONDS", 10), 1, 60)) * time.Second
+}
+
+// ResumeQueues reports whether queues interrupted by a restart start playing
+// again on their own. When off they continue the next time someone plays.
+//
+//	RESUME_QUEUES  true or false (default false)
+func ResumeQueues() bool {
+	return envBool("RESUME_QUEUES", false)
+}
+
+// PrefetchBuffer is how much of the next track is decoded while the current
+// one is still playing, so the next one starts without a gap
+//
+//	PREFETCH_SECONDS  0-60 (default 10), 0 opens each track only once the previous one ends
+func PrefetchBuffer() time.Duration {
+	return time.Duration(clamp(envInt("PREFETCH_SECONDS", 10), 0, 60)) * time.Second
+}
@@ -0,0 +1,152 @@
+package context
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// OptionType is how a command option is parsed and registered with Discord
+type OptionType int
+
+const (
+	OptionString     OptionType = iota
+	OptionInteger               // whole number, optionally limited to MinValue-MaxValue
+	OptionAttachment            // uploaded file, standardised to its URL, see Context.Attachment
+)
+
+// Option describes one argument of a command
+type Option struct {
+	Name        string
+	Description string
+	Type        OptionType
+	Required    bool
+	Aliases     []string // older names still accepted from raw arguments
+	Choices     []string // allowed values for string options, matched case-insensitively
+	MinValue    *int
+	MaxValue    *int
+
+	// Normalize cleans a string value after trimming, e.g. stripping a prefix
+	Normalize func(value string) string
+}
+
+// Command declares a command once for slash registration, message parsing,
+// argument standardisation, help output and dispatch
+type Command struct {
+	Name        string
+	Aliases     []string // extra names accepted for ! commands
+	Description string
+	Options     []Option
+	Permissions int64 // discordgo permission bits the caller needs, 0 for everyone
+	Handler     func(ctx *Context)
+}
+
+// Usage returns the command's arguments as shown in help, e.g. "<from> <to>"
+func (c *Command) Usage() string {
+	var parts []string
+	for _, option := range c.Options {
+		label := option.Name
+		if len(option.Choices) > 0 {
+			label = strings.Join(option.Choices, "|")
+		}
+		if option.Required {
+			parts = append(parts, "<"+label+">")
+		} else {
+			parts = append(parts, "["+label+"]")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Validate checks the standardised arguments against the declared options and
+// returns a message for the caller when they don't fit. prefix is how the
+// command was invoked, "/" or "!".
+func (c *Command) Validate(prefix string, arguments map[string]string) (string, bool) {
+	for _, option := range c.Options {
+		value := arguments[option.Name]
+		if value == "" {
+			if option.Required {
+				return "Usage: " + prefix + strings.TrimSpace(c.Name+" "+c.Usage()), false
+			}
+			continue
+		}
+
+		switch option.Type {
+		case OptionInteger:
+			number, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Sprintf("%s must be a whole number", option.Name), false
+			}
+			if (option.MinValue != nil && number < *option.MinValue) || (option.MaxValue != nil && number > *option.MaxValue) {
+				return fmt.Sprintf("%s must be %s", option.Name, rangeText(option.MinValue, option.MaxValue)), false
+			}
+		case OptionString:
+			if len(option.Choices) > 0 && !containsFold(option.Choices, value) {
+				return fmt.Sprintf("%s must be one of %s", option.Name, strings.Join(option.Choices, ", ")), false
+			}
+		}
+	}
+	return "", true
+}
+
+var (
+	commands      []*Command
+	commandIndex  = make(map[string]*Command)
+	commandsMutex sync.RWMutex
+)
+
+// RegisterCommands adds commands to the registry, names and aliases must be unique
+func RegisterCommands(list ...*Command) {
+	commandsMutex.Lock()
+	defer commandsMutex.Unlock()
+
+	for _, command := range list {
+		for _, name := range append([]string{command.Name}, command.Aliases...) {
+			if _, exists := commandIndex[name]; exists {
+				panic("command registered twice: " + name)
+			}
+			commandIndex[name] = command
+		}
+		commands = append(commands, command)
+	}
+}
+
+// LookupCommand returns the command registered under name or one of its aliases
+func LookupCommand(name string) (*Command, bool) {
+	commandsMutex.RLock()
+	defer commandsMutex.RUnlock()
+
+	command, ok := commandIndex[strings.ToLower(name)]
+	return command, ok
+}
+
+// Commands returns every registered command in registration order
+func Commands() []*Command {
+	commandsMutex.RLock()
+	defer commandsMutex.RUnlock()
+
+	return append([]*Command(nil), commands...)
+}
+
+// return "between min and max", "at least min" or "at most max"
+func rangeText(min, max *int) string {
+	switch {
+	case min != nil && max != nil:
+		return fmt.Sprintf("between %d and %d", *min, *max)
+	case min != nil:
+		return fmt.Sprintf("at least %d", *min)
+	default:
+		return fmt.Sprintf("at most %d", *max)
+	}
+}
+
+// report whether list contains value ignoring case
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package context
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// commands are built as plain data here, registering them would add them to
+// the bot's global registry and to the slash commands it sends to Discord
+
+func TestMessageArgumentsFollowDeclaredOptions(t *testing.T) {
+	minimum := 1
+	command := &Command{
+		Name: "testmove",
+		Options: []Option{
+			{Name: "from", Type: OptionInteger, Required: true, MinValue: &minimum},
+			{Name: "rest", Description: "everything after from"},
+		},
+	}
+
+	ctx := &Context{
+		SourceType:   SourceTypeMessage,
+		Message:      &discordgo.MessageCreate{Message: &discordgo.Message{Content: "!testmove 3  two words"}},
+		Arguments:    make(map[string]string),
+		ArgumentsRaw: make(map[string]any),
+	}
+	ctx.argumentsFromMessage(command)
+	ctx.standardiseOptions(command)
+
+	if ctx.Arguments["from"] != "3" || ctx.Arguments["rest"] != "two words" {
+		t.Fatalf("Arguments = %v; want from=3 rest=\"two words\"", ctx.Arguments)
+	}
+
+	if _, ok := command.Validate("!", ctx.Arguments); !ok {
+		t.Fatal("Validate() = false; want true")
+	}
+	if message, ok := command.Validate("!", map[string]string{"from": "0"}); ok || message != "from must be at least 1" {
+		t.Fatalf("Validate(from=0) = %q, %v; want range error", message, ok)
+	}
+	if message, _ := command.Validate("!", map[string]string{}); message != "Usage: !testmove <from> [rest]" {
+		t.Fatalf("Validate(empty) = %q; want usage", message)
+	}
+}
+
+func TestMessageAttachmentFillsAttachmentOption(t *testing.T) {
+	command := &Command{
+		Name: "testplay",
+		Options: []Option{
+			{Name: "url"},
+			{Name: "file", Type: OptionAttachment},
+		},
+	}
+
+	attachment := &discordgo.MessageAttachment{URL: "https://cdn.discordapp.com/attachments/1/2/song.flac", Size: 1024}
+	ctx := &Context{
+		SourceType: SourceTypeMessage,
+		Message: &discordgo.MessageCreate{Message: &discordgo.Message{
+			Content:     "!testplay",
+			Attachments: []*discordgo.MessageAttachment{attachment},
+		}},
+		Arguments:    make(map[string]string),
+		ArgumentsRaw: make(map[string]any),
+	}
+	ctx.argumentsFromMessage(command)
+	ctx.standardiseOptions(command)
+
+	if ctx.Arguments["url"] != "" {
+		t.Errorf("Arguments[url] = %q; want empty", ctx.Arguments["url"])
+	}
+	if ctx.Arguments["file"] != attachment.URL {
+		t.Errorf("Arguments[file] = %q; want %q", ctx.Arguments["file"], attachment.URL)
+	}
+	if ctx.Attachment("file") != attachment {
+		t.Error("Attachment(file) did not return the message attachment")
+	}
+}
+
+func TestValidateChoicesAndRange(t *testing.T) {
+	minimum, maximum := 0, 200
+	command := &Command{
+		Name: "testloop",
+		Options: []Option{
+			{Name: "mode", Choices: []string{"off", "track", "queue"}},
+			{Name: "level", Type: OptionInteger, MinValue: &minimum, MaxValue: &maximum},
+		},
+	}
+
+	if usage := command.Usage(); usage != "[off|track|queue] [level]" {
+		t.Errorf("Usage() = %q; want %q", usage, "[off|track|queue] [level]")
+	}
+	if _, ok := command.Validate("/", map[string]string{"mode": "Queue", "level": "200"}); !ok {
+		t.Error("Validate(mode=Queue, level=200) = false; want true")
+	}
+	if message, ok := command.Validate("/", map[string]string{"mode": "shuffle"}); ok || message != "mode must be one of off, track, queue" {
+		t.Errorf("Validate(mode=shuffle) = %q, %v; want choices error", message, ok)
+	}
+	if message, ok := command.Validate("/", map[string]string{"level": "201"}); ok || message != "level must be between 0 and 200" {
+		t.Errorf("Validate(level=201) = %q, %v; want range error", message, ok)
+	}
+	if message, ok := command.Validate("/", map[string]string{"level": "loud"}); ok || message != "level must be a whole number" {
+		t.Errorf("Validate(level=loud) = %q, %v; want number error", message, ok)
+	}
+}
@@ -1,6 +1,10 @@
 package context
 
 import (
+	"log/slog"
+
+	"github.com/ekkolyth/ekko-bot/internal/logging"
+
 	"github.com/bwmarrin/discordgo"
 )
 
@@ -21,6 +25,8 @@ type Context struct {
 	// Web-specific fields for Discord identity attribution
 	RequesterDiscordUserID string // Discord user ID from identity mapping (for web actions)
 	RequesterTag           string // Discord display tag from identity mapping (for web actions)
+	OnWebReply             func(message string) // Receives replies for web actions instead of sending them to Discord
+	RequestID              string // API request that sent the command (for web actions)
 }
 
 type CommandSourceType int
@@ -30,8 +36,24 @@ const (
 	SourceTypeInteraction                   // Slash commands
 	SourceTypeMessage                       // Text commands
 	SourceTypeWeb                           // Web-triggered actions
+	SourceTypeSystem                        // Started by the bot itself, e.g. resuming after a restart
 )
 
+func (t CommandSourceType) String() string {
+	switch t {
+	case SourceTypeInteraction:
+		return "interaction"
+	case SourceTypeMessage:
+		return "message"
+	case SourceTypeWeb:
+		return "web"
+	case SourceTypeSystem:
+		return "system"
+	default:
+		return "unknown"
+	}
+}
+
 
 // Getters
 
@@ -68,9 +90,47 @@ func (ctx *Context) GetSourceType() int {
 	return int(ctx.SourceType)
 }
 
+// Attachment returns the file passed for an attachment option, nil if none was
+func (ctx *Context) Attachment(name string) *discordgo.MessageAttachment {
+	raw, _ := ctx.getArgumentRaw(name)
+	attachment, _ := raw.(*discordgo.MessageAttachment)
+	return attachment
+}
+
+// CommandPrefix returns how the caller invokes commands, "!" for messages and "/" otherwise
+func (ctx *Context) CommandPrefix() string {
+	if ctx.SourceType == SourceTypeMessage {
+		return "!"
+	}
+	return "/"
+}
+
+// Logger returns a logger tagged with the command's guild, voice channel, user and request
+func (ctx *Context) Logger() *slog.Logger {
+	userID := ctx.RequesterDiscordUserID
+	if ctx.User != nil {
+		userID = ctx.User.ID
+	}
+	return logging.With(
+		"guild_id", ctx.GuildID,
+		"voice_channel_id", ctx.VoiceChannelID,
+		"user_id", userID,
+		"command", ctx.CommandName,
+		"source", ctx.SourceType.String(),
+		"request_id", ctx.RequestID,
+	)
+}
+
 // Setters
 
 func (ctx *Context) Reply(message string) {
+	if ctx.SourceType == SourceTypeWeb || ctx.SourceType == SourceTypeSystem {
+		if ctx.OnWebReply != nil {
+			ctx.OnWebReply(message)
+		}
+		return
+	}
+
 	if ctx.SourceType == SourceTypeInteraction && !ctx.InteractionResponded {
 		ctx.Session.InteractionRespond(ctx.Interaction.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -102,6 +162,12 @@ func NewInteractionContext(s *discordgo.Session, i *discordgo.InteractionCreate)
 	if data := i.ApplicationCommandData(); data.Name != "" {
 		for _, option := range data.Options {
 			ctx.ArgumentsRaw[option.Name] = option.Value
+			// attachment options only carry an ID, the file itself is in the resolved data
+			if option.Type == discordgo.ApplicationCommandOptionAttachment && data.Resolved != nil {
+				if id, ok := option.Value.(string); ok {
+					ctx.ArgumentsRaw[option.Name] = data.Resolved.Attachments[id]
+				}
+			}
 		}
 	}
 	if ctx.User == nil && i.Member != nil {
@@ -0,0 +1,25 @@
+package context
+
+import "strings"
+
+// LoopMode controls what happens to a track once it finishes playing
+type LoopMode string
+
+const (
+	LoopOff   LoopMode = "off"   // finished tracks are discarded
+	LoopTrack LoopMode = "track" // the current track is replayed
+	LoopQueue LoopMode = "queue" // finished tracks go back to the end of the queue
+)
+
+// ParseLoopMode converts user input to a LoopMode
+func ParseLoopMode(value string) (LoopMode, bool) {
+	switch LoopMode(strings.ToLower(strings.TrimSpace(value))) {
+	case LoopOff:
+		return LoopOff, true
+	case LoopTrack:
+		return LoopTrack, true
+	case LoopQueue:
+		return LoopQueue, true
+	}
+	return "", false
+}
@@ -3,6 +3,7 @@ package context
 import (
 	"strconv"
 	"strings"
+	"unicode"
 )
 
 
@@ -17,113 +18,131 @@ func (ctx *Context) ArgumentstoString() string {
 	return output
 }
 
-// Convert raw arguments to standard types (not sanitised)
+// Convert raw arguments to the declared option types (not sanitised)
 func (ctx *Context) standardiseArguments() {
-	switch ctx.CommandName {
-case "play": // url string (also support old "song" parameter name)
-    var urlValue string
-    // Try "url" first (new format), then "song" (old format)
-    if val, exists := ctx.getArgumentRaw("url"); exists {
-        if strVal, ok := val.(string); ok {
-            urlValue = strings.TrimSpace(strVal)
-        }
-    } else if val, exists := ctx.getArgumentRaw("song"); exists {
-        // Support old command format that used "song" parameter
-        if strVal, ok := val.(string); ok {
-            urlValue = strings.TrimSpace(strVal)
-        }
-    }
-    // Strip any leading "/play " prefix that Discord might include
-    if trimmed, ok := strings.CutPrefix(urlValue, "/play "); ok {
-        urlValue = trimmed
-    }
-    ctx.Arguments["url"] = strings.TrimSpace(urlValue)
-	case "search": // query string
-		if val, exists := ctx.getArgumentRaw("query"); exists {
-			if strVal, ok := val.(string); ok {
-				ctx.Arguments["query"] = strVal
-			} else {
-				ctx.Arguments["query"] = ""
+	command, ok := LookupCommand(ctx.CommandName)
+	if !ok {
+		return
+	}
+	ctx.standardiseOptions(command)
+}
+
+// Convert raw arguments to the types command declares
+func (ctx *Context) standardiseOptions(command *Command) {
+	for _, option := range command.Options {
+		key := option.Name
+		if _, exists := ctx.getArgumentRaw(key); !exists {
+			// older clients may still send a previous option name
+			for _, alias := range option.Aliases {
+				if _, exists := ctx.getArgumentRaw(alias); exists {
+					key = alias
+					break
+				}
 			}
-		} else {
-			ctx.Arguments["query"] = ""
 		}
 
-	case "volume": // level int (0-200)
-		if val, exists := ctx.getArgumentRaw("level"); exists {
-			switch v := val.(type) {
-			case int:
-				ctx.Arguments["level"] = strconv.Itoa(v)
-			case float64:
-				ctx.Arguments["level"] = strconv.Itoa(int(v))
-			case string:
-				ctx.Arguments["level"] = strings.TrimSpace(v)
-			default:
-				ctx.Arguments["level"] = ""
+		switch option.Type {
+		case OptionInteger:
+			ctx.Arguments[option.Name] = ctx.integerArgument(key)
+		case OptionAttachment:
+			ctx.Arguments[option.Name] = ""
+			if attachment := ctx.Attachment(key); attachment != nil {
+				ctx.Arguments[option.Name] = attachment.URL
 			}
-
-		} else {
-			ctx.Arguments["level"] = ""
-		}
-	case "nuke": // count int (1-100)
-		if val, exists := ctx.getArgumentRaw("count"); exists {
-			switch v := val.(type) {
-			case int:
-				ctx.Arguments["count"] = strconv.Itoa(v)
-			case float64:
-				ctx.Arguments["count"] = strconv.Itoa(int(v))
-			case string:
-				ctx.Arguments["count"] = strings.TrimSpace(v)
-			default:
-				ctx.Arguments["count"] = ""
+		default:
+			value := ""
+			if raw, exists := ctx.getArgumentRaw(key); exists {
+				if strVal, ok := raw.(string); ok {
+					value = strings.TrimSpace(strVal)
+				}
 			}
-
-		} else {
-			ctx.Arguments["count"] = ""
+			if option.Normalize != nil {
+				value = strings.TrimSpace(option.Normalize(value))
+			}
+			if len(option.Choices) > 0 {
+				value = strings.ToLower(value)
+			}
+			ctx.Arguments[option.Name] = value
 		}
 	}
+}
 
+// Convert a raw integer argument to its string form, empty if missing or invalid
+func (ctx *Context) integerArgument(key string) string {
+	val, exists := ctx.getArgumentRaw(key)
+	if !exists {
+		return ""
+	}
+	switch v := val.(type) {
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.Itoa(int(v))
+	case string:
+		return strings.TrimSpace(v)
+	default:
+		return ""
+	}
 }
 
 func (ctx *Context) determineCommandNameFromMessage() {
-	command := strings.Fields(ctx.GetMessage().Content)[0]
-	if len(command) > 0 && command[0] == '!' {
-		ctx.CommandName = command[1:]
+	fields := strings.Fields(ctx.GetMessage().Content)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "!") {
+		ctx.CommandName = ""
 		return
 	}
-	ctx.CommandName = ""
-}
 
+	name := strings.ToLower(fields[0][1:])
+	if command, ok := LookupCommand(name); ok {
+		name = command.Name // aliases run as the command they belong to
+	}
+	ctx.CommandName = name
+}
 
+// Split the message text after the command into the declared options. Each
+// option takes one word, the last one takes the rest of the line.
 func (ctx *Context) determineArgumentsFromMessage() {
 	// presume not sanitised
-	switch ctx.CommandName {
-	case "play":
-		// everything after !play is the url
-		if len(ctx.Message.Content) > 6 {
-			ctx.ArgumentsRaw["url"] = ctx.Message.Content[6:]
-		} else {
-			ctx.ArgumentsRaw["url"] = ""
-		}
-	case "search":
-		if len(ctx.Message.Content) > 8 {
-			ctx.ArgumentsRaw["query"] = ctx.Message.Content[8:]
-		} else {
-			ctx.ArgumentsRaw["query"] = ""
+	command, ok := LookupCommand(ctx.CommandName)
+	if !ok {
+		return
+	}
+	ctx.argumentsFromMessage(command)
+}
+
+// Split the message text into command's options
+func (ctx *Context) argumentsFromMessage(command *Command) {
+
+	// files are attached to the message rather than typed, in option order
+	var words []Option
+	attachments := ctx.Message.Attachments
+	for _, option := range command.Options {
+		if option.Type != OptionAttachment {
+			words = append(words, option)
+			continue
 		}
-	case "volume":
-		if len(ctx.Message.Content) > 8 {
-			ctx.ArgumentsRaw["level"] = ctx.Message.Content[8:]
-		} else {
-			ctx.ArgumentsRaw["level"] = ""
+		if len(attachments) > 0 {
+			ctx.ArgumentsRaw[option.Name] = attachments[0]
+			attachments = attachments[1:]
 		}
-	case "nuke":
-		if len(ctx.Message.Content) > 6 {
-			ctx.ArgumentsRaw["count"] = ctx.Message.Content[6:]
-		} else {
-			ctx.ArgumentsRaw["count"] = ""
+	}
+
+	_, rest := cutWord(ctx.Message.Content) // drop the command itself
+	for i, option := range words {
+		if i == len(words)-1 {
+			ctx.ArgumentsRaw[option.Name] = rest
+			break
 		}
-	default:
-		// No arguments to parse for other commands
+		ctx.ArgumentsRaw[option.Name], rest = cutWord(rest)
+	}
+}
+
+// split off the first word, both parts trimmed
+func cutWord(text string) (string, string) {
+	text = strings.TrimSpace(text)
+	end := strings.IndexFunc(text, unicode.IsSpace)
+	if end < 0 {
+		return text, ""
 	}
+	return text[:end], strings.TrimSpace(text[end:])
 }
@@ -0,0 +1,418 @@
+package context
+
+import (
+	"sync"
+	"time"
+)
+
+// PlayerState is a point-in-time copy of a player's playback state
+type PlayerState struct {
+	NowPlaying *TrackInfo
+	Position   time.Duration // offset into the now playing track
+	Playing    bool
+	Paused     bool
+	Volume     float64
+}
+
+// Player owns the playback goroutine and signalling for a single queue key
+type Player struct {
+	queueKey string
+
+	mu         sync.Mutex
+	running    bool
+	stopping   bool
+	// set once the loop decided to leave, Play then queues restart instead
+	// of waking it
+	exiting bool
+	restart func(p *Player)
+	paused     bool
+	volume     float64
+	nowPlaying *TrackInfo
+	position   time.Duration
+
+	// pending seek for the current track, set by Seek
+	seeking bool
+	seekTo  time.Duration
+
+	// closed when the current track should end (skip or stop)
+	interrupt chan struct{}
+	// closed when playback resumes, replaced on every pause
+	resume chan struct{}
+	// signalled when Play or Stop is called on a running player
+	wake chan struct{}
+	// closed when the playback goroutine exits, replaced on every Play
+	done chan struct{}
+}
+
+// NewPlayer returns an idle player for the queue key
+func NewPlayer(queueKey string, volume float64) *Player {
+	return &Player{
+		queueKey: queueKey,
+		volume:   volume,
+		resume:   closedChannel(),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+var (
+	players      = make(map[string]*Player)
+	playersMutex sync.Mutex
+)
+
+// GetPlayer returns the player for the queue key, creating it if needed.
+// New players start at the volume stored in the queue store.
+func GetPlayer(queueKey string) *Player {
+	playersMutex.Lock()
+	defer playersMutex.Unlock()
+
+	if player, ok := players[queueKey]; ok {
+		return player
+	}
+
+	volume := 1.0
+	if store := GetQueueStore(); store != nil {
+		if stored, err := store.GetVolume(queueKey); err == nil {
+			volume = stored
+		}
+	}
+
+	player := NewPlayer(queueKey, volume)
+	players[queueKey] = player
+	return player
+}
+
+// Players returns every player created so far
+func Players() []*Player {
+	playersMutex.Lock()
+	defer playersMutex.Unlock()
+
+	list := make([]*Player, 0, len(players))
+	for _, player := range players {
+		list = append(list, player)
+	}
+	return list
+}
+
+// LookupPlayer returns the player for the queue key without creating one
+func LookupPlayer(queueKey string) (*Player, bool) {
+	playersMutex.Lock()
+	defer playersMutex.Unlock()
+
+	player, ok := players[queueKey]
+	return player, ok
+}
+
+// QueueKey returns the queue key the player belongs to
+func (p *Player) QueueKey() string {
+	return p.queueKey
+}
+
+// Play starts run on the player's goroutine. Returns false if it is already
+// running; a pending Stop is cancelled and an idle loop is woken up. A loop
+// that is already leaving, see BeginExit, is followed by run instead.
+func (p *Player) Play(run func(p *Player)) bool {
+	p.mu.Lock()
+	if p.running {
+		p.stopping = false
+		if p.exiting {
+			p.restart = run
+			p.mu.Unlock()
+			return true
+		}
+		p.wakeLocked()
+		p.mu.Unlock()
+		return false
+	}
+	p.running = true
+	p.stopping = false
+	done := make(chan struct{})
+	p.done = done
+	p.mu.Unlock()
+
+	go func() {
+		for run != nil {
+			run(p)
+
+			p.mu.Lock()
+			run, p.restart = p.restart, nil
+			p.exiting = false
+			p.nowPlaying = nil
+			p.position = 0
+			p.interrupt = nil
+			p.seeking = false
+			if run == nil {
+				p.running = false
+				p.stopping = false
+			}
+			p.mu.Unlock()
+		}
+		close(done)
+	}()
+	return true
+}
+
+// BeginExit marks the loop as leaving. Play calls from here on start a new run
+// once this one returns, so the loop must check for queued tracks afterwards.
+func (p *Player) BeginExit() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.exiting = true
+}
+
+// CancelExit keeps the loop running after BeginExit found tracks were queued.
+// A restart Play asked for is dropped, this loop plays them.
+func (p *Player) CancelExit() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.exiting = false
+	p.restart = nil
+}
+
+// StartTrack marks info as now playing and returns a channel that is
+// closed when the track is skipped or the player is stopped
+func (p *Player) StartTrack(info *TrackInfo) <-chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.nowPlaying = info
+	p.position = 0
+	p.interrupt = make(chan struct{})
+	// a wake left by an earlier Play is answered by this track, it mustn't
+	// cut the next idle short
+	select {
+	case <-p.wake:
+	default:
+	}
+	return p.interrupt
+}
+
+// FinishTrack clears the now playing track
+func (p *Player) FinishTrack() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.nowPlaying = nil
+	p.position = 0
+	p.interrupt = nil
+}
+
+// SetPosition records how far into the current track playback is
+func (p *Player) SetPosition(position time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.position = position
+}
+
+// Skip ends the current track. Returns false if nothing is playing.
+func (p *Player) Skip() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.interruptLocked()
+}
+
+// Seek restarts the current track from offset. Returns false if nothing is playing.
+func (p *Player) Seek(offset time.Duration) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.interrupt == nil || p.stopping {
+		return false
+	}
+	p.seeking = true
+	p.seekTo = offset
+	p.interruptLocked()
+	return true
+}
+
+// TakeSeek returns and clears the pending seek offset
+func (p *Player) TakeSeek() (time.Duration, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.seeking {
+		return 0, false
+	}
+	p.seeking = false
+	return p.seekTo, true
+}
+
+// Stop ends the current track and asks the playback loop to exit
+func (p *Player) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.running {
+		p.stopping = true
+		p.restart = nil
+		p.wakeLocked()
+	}
+	p.setPausedLocked(false)
+	p.interruptLocked()
+}
+
+// Wait blocks until the playback goroutine exits or timeout passes. Returns
+// false on timeout.
+func (p *Player) Wait(timeout time.Duration) bool {
+	p.mu.Lock()
+	running, done := p.running, p.done
+	p.mu.Unlock()
+	if !running {
+		return true
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// Idle blocks the playback loop until Play or Stop is called again or
+// timeout passes. Returns false on timeout.
+func (p *Player) Idle(timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-p.wake:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// Stopping reports whether Stop was called on the running loop
+func (p *Player) Stopping() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.stopping
+}
+
+// Pause pauses playback. Returns false if already paused.
+func (p *Player) Pause() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.paused {
+		return false
+	}
+	p.setPausedLocked(true)
+	return true
+}
+
+// Resume resumes playback. Returns false if not paused.
+func (p *Player) Resume() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.paused {
+		return false
+	}
+	p.setPausedLocked(false)
+	return true
+}
+
+// TogglePause flips the pause state and returns the new state
+func (p *Player) TogglePause() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.setPausedLocked(!p.paused)
+	return p.paused
+}
+
+// WaitWhilePaused blocks until playback is resumed or interrupt is closed.
+// Returns false if it was interrupted.
+func (p *Player) WaitWhilePaused(interrupt <-chan struct{}) bool {
+	p.mu.Lock()
+	resume := p.resume
+	p.mu.Unlock()
+
+	select {
+	case <-resume:
+		return true
+	case <-interrupt:
+		return false
+	}
+}
+
+// SetVolume sets the volume factor (1.0 = 100%)
+func (p *Player) SetVolume(value float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.volume = value
+}
+
+// Volume returns the volume factor (1.0 = 100%)
+func (p *Player) Volume() float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.volume
+}
+
+// State returns a copy of the current playback state
+func (p *Player) State() PlayerState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return PlayerState{
+		NowPlaying: p.nowPlaying,
+		Position:   p.position,
+		Playing:    p.running,
+		Paused:     p.paused,
+		Volume:     p.volume,
+	}
+}
+
+// close the interrupt channel once, caller holds the lock
+func (p *Player) interruptLocked() bool {
+	if p.interrupt == nil {
+		return false
+	}
+	select {
+	case <-p.interrupt:
+		return false
+	default:
+		close(p.interrupt)
+		return true
+	}
+}
+
+// update pause state and signalling, caller holds the lock
+func (p *Player) setPausedLocked(value bool) {
+	if p.paused == value {
+		return
+	}
+	p.paused = value
+	if value {
+		p.resume = make(chan struct{})
+	} else {
+		close(p.resume)
+	}
+}
+
+// signal an idle loop without blocking, caller holds the lock
+func (p *Player) wakeLocked() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// return an already closed channel
+func closedChannel() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}
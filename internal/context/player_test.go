@@ -0,0 +1,232 @@
+package context
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPlayerPauseBlocksUntilResume(t *testing.T) {
+	player := NewPlayer("guild:voice", 1.0)
+	interrupt := player.StartTrack(&TrackInfo{URL: "https://youtu.be/dQw4w9WgXcQ"})
+
+	if !player.Pause() {
+		t.Fatal("Pause() = false; want true")
+	}
+	if player.Pause() {
+		t.Fatal("second Pause() = true; want false")
+	}
+
+	resumed := make(chan bool, 1)
+	go func() {
+		resumed <- player.WaitWhilePaused(interrupt)
+	}()
+
+	select {
+	case <-resumed:
+		t.Fatal("WaitWhilePaused returned while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	player.Resume()
+
+	select {
+	case ok := <-resumed:
+		if !ok {
+			t.Fatal("WaitWhilePaused() = false after resume; want true")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("WaitWhilePaused did not return after resume")
+	}
+}
+
+func TestPlayerSkipInterruptsPausedTrack(t *testing.T) {
+	player := NewPlayer("guild:voice", 1.0)
+	interrupt := player.StartTrack(&TrackInfo{URL: "https://youtu.be/dQw4w9WgXcQ"})
+	player.Pause()
+
+	if !player.Skip() {
+		t.Fatal("Skip() = false; want true")
+	}
+	if player.Skip() {
+		t.Fatal("second Skip() = true; want false")
+	}
+	if player.WaitWhilePaused(interrupt) {
+		t.Fatal("WaitWhilePaused() = true after skip; want false")
+	}
+}
+
+func TestPlayerStopEndsLoop(t *testing.T) {
+	player := NewPlayer("guild:voice", 1.0)
+	done := make(chan struct{})
+	tracks := 0
+
+	started := player.Play(func(player *Player) {
+		defer close(done)
+		for !player.Stopping() {
+			tracks++
+			interrupt := player.StartTrack(&TrackInfo{URL: "https://youtu.be/dQw4w9WgXcQ"})
+			<-interrupt
+			player.FinishTrack()
+		}
+	})
+	if !started {
+		t.Fatal("Play() = false; want true")
+	}
+	if player.Play(func(*Player) {}) {
+		t.Fatal("Play() while running = true; want false")
+	}
+
+	for player.State().NowPlaying == nil {
+		time.Sleep(time.Millisecond)
+	}
+	player.Stop()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("loop did not exit after Stop")
+	}
+	if tracks != 1 {
+		t.Errorf("played %d tracks; want 1", tracks)
+	}
+}
+
+func TestPlayerWaitReturnsAfterStop(t *testing.T) {
+	player := NewPlayer("guild:voice", 1.0)
+	if !player.Wait(time.Millisecond) {
+		t.Fatal("Wait() on idle player = false; want true")
+	}
+
+	player.Play(func(player *Player) {
+		for !player.Stopping() {
+			<-player.StartTrack(&TrackInfo{URL: "https://youtu.be/dQw4w9WgXcQ"})
+			player.FinishTrack()
+		}
+	})
+	for player.State().NowPlaying == nil {
+		time.Sleep(time.Millisecond)
+	}
+
+	if player.Wait(10 * time.Millisecond) {
+		t.Fatal("Wait() while playing = true; want false")
+	}
+	player.Stop()
+	if !player.Wait(time.Second) {
+		t.Fatal("Wait() after Stop = false; want true")
+	}
+	if player.State().Playing {
+		t.Error("player still running after Wait")
+	}
+}
+
+func TestPlayerVolume(t *testing.T) {
+	player := NewPlayer("guild:voice", 0.25)
+	if got := player.Volume(); got != 0.25 {
+		t.Errorf("Volume() = %v; want 0.25", got)
+	}
+	player.SetVolume(1.5)
+	if got := player.State().Volume; got != 1.5 {
+		t.Errorf("State().Volume = %v; want 1.5", got)
+	}
+}
+
+func TestPlayerSeekInterruptsTrack(t *testing.T) {
+	player := NewPlayer("guild:voice", 1.0)
+	if player.Seek(time.Minute) {
+		t.Fatal("Seek() with nothing playing = true; want false")
+	}
+
+	interrupt := player.StartTrack(&TrackInfo{URL: "https://youtu.be/dQw4w9WgXcQ"})
+	if !player.Seek(90 * time.Second) {
+		t.Fatal("Seek() = false; want true")
+	}
+	select {
+	case <-interrupt:
+	default:
+		t.Fatal("Seek did not interrupt the track")
+	}
+
+	offset, ok := player.TakeSeek()
+	if !ok || offset != 90*time.Second {
+		t.Errorf("TakeSeek() = %v, %v; want 1m30s, true", offset, ok)
+	}
+	if _, ok := player.TakeSeek(); ok {
+		t.Error("second TakeSeek() = true; want false")
+	}
+}
+
+func TestPlayerIdleWakesOnPlay(t *testing.T) {
+	player := NewPlayer("guild:voice", 1.0)
+	woken := make(chan bool, 1)
+	release := make(chan struct{})
+
+	player.Play(func(player *Player) {
+		woken <- player.Idle(time.Second)
+		<-release
+	})
+	defer close(release)
+
+	player.Play(func(*Player) {})
+
+	select {
+	case ok := <-woken:
+		if !ok {
+			t.Fatal("Idle() = false; want true after Play")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Idle did not return")
+	}
+
+	if player.Idle(10 * time.Millisecond) {
+		t.Error("Idle() = true with no Play; want false")
+	}
+}
+
+func TestPlayerPlayAfterExitRunsAgain(t *testing.T) {
+	player := NewPlayer("guild:voice", 1.0)
+	exiting := make(chan struct{})
+	leave := make(chan struct{})
+	restarted := make(chan struct{})
+
+	player.Play(func(player *Player) {
+		player.BeginExit()
+		close(exiting)
+		<-leave
+	})
+	<-exiting
+
+	// a track queued after the loop's last check
+	if !player.Play(func(*Player) { close(restarted) }) {
+		t.Fatal("Play() while the loop is leaving = false; want true")
+	}
+	close(leave)
+
+	select {
+	case <-restarted:
+	case <-time.After(time.Second):
+		t.Fatal("Play() after BeginExit never ran")
+	}
+	if !player.Wait(time.Second) {
+		t.Fatal("player still running after the restarted loop returned")
+	}
+}
+
+func TestPlayerStartTrackDrainsWake(t *testing.T) {
+	player := NewPlayer("guild:voice", 1.0)
+	release := make(chan struct{})
+	idle := make(chan bool, 1)
+
+	player.Play(func(player *Player) {
+		<-release
+		player.StartTrack(&TrackInfo{URL: "https://youtu.be/dQw4w9WgXcQ"})
+		player.FinishTrack()
+		idle <- player.Idle(10 * time.Millisecond)
+	})
+	// answered by the track the loop starts next
+	player.Play(func(*Player) {})
+	close(release)
+
+	if <-idle {
+		t.Error("Idle() after a track = true from a stale wake; want false")
+	}
+}
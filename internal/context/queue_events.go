@@ -0,0 +1,87 @@
+package context
+
+import (
+	stdctx "context"
+	"encoding/json"
+	"time"
+)
+
+// QueueEventType names a change to a queue
+type QueueEventType string
+
+const (
+	EventTrackAdded     QueueEventType = "track_added"
+	EventTrackRemoved   QueueEventType = "track_removed"
+	EventQueueReordered QueueEventType = "queue_reordered"
+	EventNowPlaying     QueueEventType = "now_playing"
+	EventPaused         QueueEventType = "paused"
+	EventResumed        QueueEventType = "resumed"
+	EventVolumeChanged  QueueEventType = "volume_changed"
+	EventQueueCleared   QueueEventType = "queue_cleared"
+	EventPositionTick   QueueEventType = "position_tick"
+)
+
+// QueueEvent is published on every queue mutation. Only the fields relevant
+// to the event type are set; a now_playing event without a track means
+// playback ended.
+type QueueEvent struct {
+	Type       QueueEventType `json:"type"`
+	Track      *TrackInfo     `json:"track,omitempty"`
+	Index      *int           `json:"index,omitempty"`
+	Volume     *float64       `json:"volume,omitempty"`
+	PositionMS *int64         `json:"position_ms,omitempty"`
+	At         int64          `json:"at"`
+}
+
+// publish event for queue, best effort since the mutation already happened
+func (store *redisQueueStore) publish(queueKey string, event QueueEvent) {
+	event.At = time.Now().UnixMilli()
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	_ = store.client.Publish(stdctx.Background(), eventsChannel(queueKey), payload).Err()
+}
+
+// stream events for queue until ctx is done
+func (store *redisQueueStore) Subscribe(ctx stdctx.Context, queueKey string) (<-chan QueueEvent, error) {
+	pubsub := store.client.Subscribe(ctx, eventsChannel(queueKey))
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, err
+	}
+
+	events := make(chan QueueEvent)
+	go func() {
+		defer close(events)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				var event QueueEvent
+				if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+					continue
+				}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// return events pub/sub channel
+func eventsChannel(queueKey string) string {
+	return "queue:events:" + queueKey
+}
@@ -1,7 +1,14 @@
 package context
 
+import "strings"
+
 // QueueKey creates a unique key for guild+voice channel combination
 func QueueKey(guildID, voiceChannelID string) string {
 	return guildID + ":" + voiceChannelID
 }
 
+// SplitQueueKey returns the guild and voice channel a queue key was made from
+func SplitQueueKey(queueKey string) (guildID, voiceChannelID string, ok bool) {
+	guildID, voiceChannelID, ok = strings.Cut(queueKey, ":")
+	return guildID, voiceChannelID, ok && guildID != "" && voiceChannelID != ""
+}
@@ -0,0 +1,95 @@
+package context
+
+import (
+	stdctx "context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// an interrupted track is only worth continuing for a while after the restart
+const resumeTTL = time.Hour
+
+// resumePoint is a track interrupted by a restart and where it stopped
+type resumePoint struct {
+	Track      *TrackInfo `json:"track"`
+	PositionMS int64      `json:"position_ms"`
+}
+
+// save the interrupted track so the next playback continues from position
+func (store *redisQueueStore) SaveResume(queueKey string, track *TrackInfo, position time.Duration) error {
+	if track == nil {
+		return errors.New("track is nil")
+	}
+	payload, err := json.Marshal(resumePoint{Track: track, PositionMS: position.Milliseconds()})
+	if err != nil {
+		return err
+	}
+	return store.client.Set(stdctx.Background(), resumeKey(queueKey), payload, resumeTTL).Err()
+}
+
+// return and forget the interrupted track, nil when there is none
+func (store *redisQueueStore) TakeResume(queueKey string) (*TrackInfo, time.Duration, error) {
+	result, err := store.client.GetDel(stdctx.Background(), resumeKey(queueKey)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, 0, nil
+		}
+		return nil, 0, err
+	}
+
+	var point resumePoint
+	if err := json.Unmarshal([]byte(result), &point); err != nil {
+		return nil, 0, err
+	}
+	if point.Track == nil {
+		return nil, 0, nil
+	}
+	return point.Track, time.Duration(point.PositionMS) * time.Millisecond, nil
+}
+
+// return the queues marked as playing
+func (store *redisQueueStore) PlayingQueues() ([]string, error) {
+	ctx := stdctx.Background()
+	keys, err := store.scanQueues(ctx, metaKey("*"))
+	if err != nil {
+		return nil, err
+	}
+
+	var playing []string
+	for _, queueKey := range keys {
+		value, err := store.readBool(metaKey(queueKey), "playing")
+		if err != nil {
+			return nil, err
+		}
+		if value {
+			playing = append(playing, queueKey)
+		}
+	}
+	return playing, nil
+}
+
+// return the queues with an interrupted track
+func (store *redisQueueStore) ResumableQueues() ([]string, error) {
+	return store.scanQueues(stdctx.Background(), resumeKey("*"))
+}
+
+// return the queue keys of every redis key matching pattern
+func (store *redisQueueStore) scanQueues(ctx stdctx.Context, pattern string) ([]string, error) {
+	prefix := strings.TrimSuffix(pattern, "*")
+
+	var queueKeys []string
+	iter := store.client.Scan(ctx, 0, pattern, 100).Iterator()
+	for iter.Next(ctx) {
+		queueKeys = append(queueKeys, strings.TrimPrefix(iter.Val(), prefix))
+	}
+	return queueKeys, iter.Err()
+}
+
+// return resume key
+func resumeKey(queueKey string) string {
+	return "queue:resume:" + queueKey
+}
@@ -0,0 +1,83 @@
+package context
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestQueueStore(t *testing.T) (QueueStore, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewRedisQueueStore(client), server
+}
+
+func TestSaveResumeTakeResume(t *testing.T) {
+	store, server := newTestQueueStore(t)
+	queueKey := QueueKey("guild", "voice")
+	track := &TrackInfo{URL: "https://youtu.be/dQw4w9WgXcQ", Title: "Never Gonna Give You Up", Duration: 213}
+
+	if err := store.SaveResume(queueKey, track, 95*time.Second+500*time.Millisecond); err != nil {
+		t.Fatalf("SaveResume() error = %v", err)
+	}
+	if ttl := server.TTL(resumeKey(queueKey)); ttl != resumeTTL {
+		t.Errorf("resume TTL = %v; want %v", ttl, resumeTTL)
+	}
+
+	resumable, err := store.ResumableQueues()
+	if err != nil {
+		t.Fatalf("ResumableQueues() error = %v", err)
+	}
+	if len(resumable) != 1 || resumable[0] != queueKey {
+		t.Errorf("ResumableQueues() = %v; want [%s]", resumable, queueKey)
+	}
+
+	got, position, err := store.TakeResume(queueKey)
+	if err != nil {
+		t.Fatalf("TakeResume() error = %v", err)
+	}
+	if got == nil || got.URL != track.URL || got.Title != track.Title {
+		t.Fatalf("TakeResume() track = %+v; want %+v", got, track)
+	}
+	if position != 95500*time.Millisecond {
+		t.Errorf("TakeResume() position = %v; want 1m35.5s", position)
+	}
+
+	// taking forgets the track so it isn't resumed twice
+	got, position, err = store.TakeResume(queueKey)
+	if err != nil || got != nil || position != 0 {
+		t.Errorf("second TakeResume() = %v, %v, %v; want nil, 0, nil", got, position, err)
+	}
+}
+
+func TestSaveResumeRejectsNilTrack(t *testing.T) {
+	store, _ := newTestQueueStore(t)
+	if err := store.SaveResume(QueueKey("guild", "voice"), nil, 0); err == nil {
+		t.Error("SaveResume(nil) error = nil; want an error")
+	}
+}
+
+func TestPlayingQueues(t *testing.T) {
+	store, _ := newTestQueueStore(t)
+	playing := QueueKey("guild", "playing")
+	stopped := QueueKey("guild", "stopped")
+
+	if err := store.SetPlaying(playing, true); err != nil {
+		t.Fatalf("SetPlaying() error = %v", err)
+	}
+	if err := store.SetPlaying(stopped, false); err != nil {
+		t.Fatalf("SetPlaying() error = %v", err)
+	}
+
+	queues, err := store.PlayingQueues()
+	if err != nil {
+		t.Fatalf("PlayingQueues() error = %v", err)
+	}
+	if len(queues) != 1 || queues[0] != playing {
+		t.Errorf("PlayingQueues() = %v; want [%s]", queues, playing)
+	}
+}
@@ -5,17 +5,25 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand"
 	"strconv"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// ErrInvalidPosition is returned by Move when a position is outside the queue
+var ErrInvalidPosition = errors.New("position out of range")
+
 // share state bewtween API and Bot
 type QueueStore interface {
 	Append(queueKey string, track *TrackInfo) error
 	PopNext(queueKey string) (*TrackInfo, error)
+	Peek(queueKey string) (*TrackInfo, error)
 	Snapshot(queueKey string) ([]*TrackInfo, error)
 	Remove(queueKey string, index int) error
+	Move(queueKey string, from, to int) error
+	Shuffle(queueKey string) error
 	Clear(queueKey string) error
 	Length(queueKey string) (int64, error)
 
@@ -27,13 +35,27 @@ type QueueStore interface {
 	GetNowPlaying(queueKey string) (*TrackInfo, error)
 	ClearNowPlaying(queueKey string) error
 
+	SetPosition(queueKey string, elapsedMS int64, startedAt time.Time) error
+	GetPosition(queueKey string) (elapsedMS int64, startedAt time.Time, err error)
+
 	SetPlaying(queueKey string, value bool) error
 	IsPlaying(queueKey string) (bool, error)
 	SetPaused(queueKey string, value bool) error
 	IsPaused(queueKey string) (bool, error)
 
 	SetVolume(queueKey string, value float64) error
+	InitVolume(queueKey string, value float64) (bool, error)
 	GetVolume(queueKey string) (float64, error)
+
+	SetLoopMode(queueKey string, mode LoopMode) error
+	GetLoopMode(queueKey string) (LoopMode, error)
+
+	Subscribe(ctx stdctx.Context, queueKey string) (<-chan QueueEvent, error)
+
+	SaveResume(queueKey string, track *TrackInfo, position time.Duration) error
+	TakeResume(queueKey string) (*TrackInfo, time.Duration, error)
+	PlayingQueues() ([]string, error)
+	ResumableQueues() ([]string, error)
 }
 
 var store QueueStore
@@ -68,7 +90,14 @@ func (store *redisQueueStore) Append(queueKey string, track *TrackInfo) error {
 		return err
 	}
 
-	return store.client.RPush(stdctx.Background(), listKey(queueKey), payload).Err()
+	length, err := store.client.RPush(stdctx.Background(), listKey(queueKey), payload).Result()
+	if err != nil {
+		return err
+	}
+
+	index := int(length) - 1
+	store.publish(queueKey, QueueEvent{Type: EventTrackAdded, Track: track, Index: &index})
+	return nil
 }
 
 // return first track from queue
@@ -81,6 +110,25 @@ func (store *redisQueueStore) PopNext(queueKey string) (*TrackInfo, error) {
 		return nil, err
 	}
 
+	track, err := decodeTrack(result)
+	if err != nil {
+		return nil, err
+	}
+
+	index := 0
+	store.publish(queueKey, QueueEvent{Type: EventTrackRemoved, Track: track, Index: &index})
+	return track, nil
+}
+
+// return first track from queue without removing it, nil when empty
+func (store *redisQueueStore) Peek(queueKey string) (*TrackInfo, error) {
+	result, err := store.client.LIndex(stdctx.Background(), listKey(queueKey), 0).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, err
+	}
 	return decodeTrack(result)
 }
 
@@ -127,13 +175,99 @@ func (store *redisQueueStore) Remove(queueKey string, index int) error {
 	for _, val := range updated {
 		pipe.RPush(ctx, listKey(queueKey), val)
 	}
-	_, execErr := pipe.Exec(ctx)
-	return execErr
+	if _, execErr := pipe.Exec(ctx); execErr != nil {
+		return execErr
+	}
+
+	removed, _ := decodeTrack(values[index])
+	store.publish(queueKey, QueueEvent{Type: EventTrackRemoved, Track: removed, Index: &index})
+	return nil
+}
+
+// move one entry within the list on the server so concurrent appends and
+// pops can't interleave with the reorder
+var moveScript = redis.NewScript(`
+local from = tonumber(ARGV[1])
+local to = tonumber(ARGV[2])
+local items = redis.call('LRANGE', KEYS[1], 0, -1)
+local count = #items
+if from < 0 or from >= count or to < 0 or to >= count then
+	return redis.error_reply('index out of range')
+end
+if from == to then
+	return count
+end
+
+local item = table.remove(items, from + 1)
+table.insert(items, to + 1, item)
+
+redis.call('DEL', KEYS[1])
+for i = 1, count, 1000 do
+	redis.call('RPUSH', KEYS[1], unpack(items, i, math.min(i + 999, count)))
+end
+return count
+`)
+
+// move track from one index to another
+func (store *redisQueueStore) Move(queueKey string, from, to int) error {
+	if from < 0 || to < 0 {
+		return fmt.Errorf("move %d -> %d: %w", from, to, ErrInvalidPosition)
+	}
+
+	err := moveScript.Run(stdctx.Background(), store.client, []string{listKey(queueKey)}, from, to).Err()
+	if err != nil {
+		var redisErr redis.Error
+		if errors.As(err, &redisErr) && redisErr.Error() == "index out of range" {
+			return fmt.Errorf("move %d -> %d: %w", from, to, ErrInvalidPosition)
+		}
+		return fmt.Errorf("move %d -> %d: %w", from, to, err)
+	}
+
+	store.publish(queueKey, QueueEvent{Type: EventQueueReordered})
+	return nil
+}
+
+// shuffle the list in place on the server so concurrent appends and pops
+// can't interleave with the reorder. math.random is seeded per call since
+// Redis seeds it identically for every script run.
+var shuffleScript = redis.NewScript(`
+local items = redis.call('LRANGE', KEYS[1], 0, -1)
+local count = #items
+if count < 2 then
+	return count
+end
+
+math.randomseed(tonumber(ARGV[1]))
+for i = count, 2, -1 do
+	local j = math.random(i)
+	items[i], items[j] = items[j], items[i]
+end
+
+redis.call('DEL', KEYS[1])
+for i = 1, count, 1000 do
+	redis.call('RPUSH', KEYS[1], unpack(items, i, math.min(i + 999, count)))
+end
+return count
+`)
+
+// shuffle queued tracks
+func (store *redisQueueStore) Shuffle(queueKey string) error {
+	if err := shuffleScript.Run(stdctx.Background(), store.client, []string{listKey(queueKey)}, rand.Int31()).Err(); err != nil {
+		return err
+	}
+
+	store.publish(queueKey, QueueEvent{Type: EventQueueReordered})
+	return nil
 }
 
 // clear queue
 func (store *redisQueueStore) Clear(queueKey string) error {
-	return store.client.Del(stdctx.Background(), listKey(queueKey)).Err()
+	if err := store.client.Del(stdctx.Background(), listKey(queueKey)).Err(); err != nil {
+		return err
+	}
+
+	store.publish(queueKey, QueueEvent{Type: EventQueueCleared})
+	return nil
 }
 
 // return queue length
@@ -175,13 +309,22 @@ func (store *redisQueueStore) ClearMetadata(queueKey string) error {
 // set now playing track
 func (store *redisQueueStore) SetNowPlaying(queueKey string, info *TrackInfo) error {
 	if info == nil {
-		return store.client.Del(stdctx.Background(), nowPlayingKey(queueKey)).Err()
+		if err := store.client.Del(stdctx.Background(), nowPlayingKey(queueKey)).Err(); err != nil {
+			return err
+		}
+		store.publish(queueKey, QueueEvent{Type: EventNowPlaying})
+		return nil
 	}
 	payload, err := json.Marshal(info)
 	if err != nil {
 		return err
 	}
-	return store.client.Set(stdctx.Background(), nowPlayingKey(queueKey), payload, 0).Err()
+	if err := store.client.Set(stdctx.Background(), nowPlayingKey(queueKey), payload, 0).Err(); err != nil {
+		return err
+	}
+
+	store.publish(queueKey, QueueEvent{Type: EventNowPlaying, Track: info})
+	return nil
 }
 
 // return now playing track
@@ -196,9 +339,54 @@ func (store *redisQueueStore) GetNowPlaying(queueKey string) (*TrackInfo, error)
 	return decodeTrack(result)
 }
 
-// clear now playing
+// clear now playing and its position
 func (store *redisQueueStore) ClearNowPlaying(queueKey string) error {
-	return store.client.Del(stdctx.Background(), nowPlayingKey(queueKey)).Err()
+	ctx := stdctx.Background()
+	_, err := store.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, nowPlayingKey(queueKey))
+		pipe.HDel(ctx, metaKey(queueKey), "position_ms", "started_at")
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	store.publish(queueKey, QueueEvent{Type: EventNowPlaying})
+	return nil
+}
+
+// set now playing position, startedAt is when the track would have started without pauses or seeks
+func (store *redisQueueStore) SetPosition(queueKey string, elapsedMS int64, startedAt time.Time) error {
+	err := store.client.HSet(stdctx.Background(), metaKey(queueKey),
+		"position_ms", strconv.FormatInt(elapsedMS, 10),
+		"started_at", strconv.FormatInt(startedAt.UnixMilli(), 10),
+	).Err()
+	if err != nil {
+		return err
+	}
+
+	store.publish(queueKey, QueueEvent{Type: EventPositionTick, PositionMS: &elapsedMS})
+	return nil
+}
+
+// return now playing position, zero when unset
+func (store *redisQueueStore) GetPosition(queueKey string) (int64, time.Time, error) {
+	values, err := store.client.HMGet(stdctx.Background(), metaKey(queueKey), "position_ms", "started_at").Result()
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	var elapsedMS int64
+	var startedAt time.Time
+	if raw, ok := values[0].(string); ok {
+		elapsedMS, _ = strconv.ParseInt(raw, 10, 64)
+	}
+	if raw, ok := values[1].(string); ok {
+		if millis, parseErr := strconv.ParseInt(raw, 10, 64); parseErr == nil {
+			startedAt = time.UnixMilli(millis)
+		}
+	}
+	return elapsedMS, startedAt, nil
 }
 
 // set playing state
@@ -211,9 +399,25 @@ func (store *redisQueueStore) IsPlaying(queueKey string) (bool, error) {
 	return store.readBool(metaKey(queueKey), "playing")
 }
 
-// set paused state
+// set paused state, publishing only when it changes
 func (store *redisQueueStore) SetPaused(queueKey string, value bool) error {
-	return store.client.HSet(stdctx.Background(), metaKey(queueKey), "paused", boolString(value)).Err()
+	previous, err := store.client.HGet(stdctx.Background(), metaKey(queueKey), "paused").Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	if err := store.client.HSet(stdctx.Background(), metaKey(queueKey), "paused", boolString(value)).Err(); err != nil {
+		return err
+	}
+	if previous == boolString(value) || (previous == "" && !value) {
+		return nil
+	}
+
+	eventType := EventResumed
+	if value {
+		eventType = EventPaused
+	}
+	store.publish(queueKey, QueueEvent{Type: eventType})
+	return nil
 }
 
 // return paused state
@@ -223,7 +427,23 @@ func (store *redisQueueStore) IsPaused(queueKey string) (bool, error) {
 
 // set volume
 func (store *redisQueueStore) SetVolume(queueKey string, value float64) error {
-	return store.client.HSet(stdctx.Background(), metaKey(queueKey), "volume", fmt.Sprintf("%f", value)).Err()
+	if err := store.client.HSet(stdctx.Background(), metaKey(queueKey), "volume", fmt.Sprintf("%f", value)).Err(); err != nil {
+		return err
+	}
+
+	store.publish(queueKey, QueueEvent{Type: EventVolumeChanged, Volume: &value})
+	return nil
+}
+
+// set volume unless the queue already has one, returns true if it was set
+func (store *redisQueueStore) InitVolume(queueKey string, value float64) (bool, error) {
+	set, err := store.client.HSetNX(stdctx.Background(), metaKey(queueKey), "volume", fmt.Sprintf("%f", value)).Result()
+	if err != nil || !set {
+		return set, err
+	}
+
+	store.publish(queueKey, QueueEvent{Type: EventVolumeChanged, Volume: &value})
+	return true, nil
 }
 
 // return volume
@@ -243,6 +463,31 @@ func (store *redisQueueStore) GetVolume(queueKey string) (float64, error) {
 	return parsed, nil
 }
 
+// set loop mode
+func (store *redisQueueStore) SetLoopMode(queueKey string, mode LoopMode) error {
+	if _, ok := ParseLoopMode(string(mode)); !ok {
+		return fmt.Errorf("invalid loop mode %q", mode)
+	}
+	return store.client.HSet(stdctx.Background(), metaKey(queueKey), "loop", string(mode)).Err()
+}
+
+// return loop mode, off when unset
+func (store *redisQueueStore) GetLoopMode(queueKey string) (LoopMode, error) {
+	result, err := store.client.HGet(stdctx.Background(), metaKey(queueKey), "loop").Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return LoopOff, nil
+		}
+		return LoopOff, err
+	}
+
+	mode, ok := ParseLoopMode(result)
+	if !ok {
+		return LoopOff, nil
+	}
+	return mode, nil
+}
+
 // return bool value from hash field
 func (store *redisQueueStore) readBool(key, field string) (bool, error) {
 	result, err := store.client.HGet(stdctx.Background(), key, field).Result()
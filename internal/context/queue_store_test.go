@@ -0,0 +1,32 @@
+package context
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMoveReportsInvalidPositions(t *testing.T) {
+	store, server := newTestQueueStore(t)
+	queueKey := QueueKey("guild", "voice")
+	for _, url := range []string{"https://youtu.be/a", "https://youtu.be/b"} {
+		if err := store.Append(queueKey, &TrackInfo{URL: url}); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	if err := store.Move(queueKey, 0, 1); err != nil {
+		t.Fatalf("Move(0, 1) error = %v", err)
+	}
+	if err := store.Move(queueKey, 0, 5); !errors.Is(err, ErrInvalidPosition) {
+		t.Errorf("Move(0, 5) error = %v; want ErrInvalidPosition", err)
+	}
+	if err := store.Move(queueKey, -1, 0); !errors.Is(err, ErrInvalidPosition) {
+		t.Errorf("Move(-1, 0) error = %v; want ErrInvalidPosition", err)
+	}
+
+	// an outage isn't the caller's fault
+	server.Close()
+	if err := store.Move(queueKey, 0, 1); err == nil || errors.Is(err, ErrInvalidPosition) {
+		t.Errorf("Move() with redis down error = %v; want a non-position error", err)
+	}
+}
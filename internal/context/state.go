@@ -3,19 +3,19 @@ package context
 import (
 	"sync"
 	"time"
-
-	"layeh.com/gopus"
 )
 
 // TrackInfo holds metadata about a track
 type TrackInfo struct {
-	URL       string
-	Title     string
-	Artist    string
-	Duration  int
-	Thumbnail string
-	AddedBy   string
-	AddedByID string
+	URL        string
+	Title      string
+	Artist     string
+	Duration   int
+	Thumbnail  string
+	AddedBy    string
+	AddedByID  string
+	SpotifyURL string `json:",omitempty"` // link the track was requested as, URL is its YouTube match
+	Live       bool   `json:",omitempty"` // live stream or radio, Duration stays 0
 }
 
 func init() {
@@ -29,32 +29,6 @@ var (
 	// Set of disabled commands
 	DisabledCommands = make(map[string]bool)
 
-	// Queue Key (guild:voiceChannel) -> Currently playing track URL
-	NowPlaying      = make(map[string]string)
-	NowPlayingMutex sync.Mutex
-
-	// Queue Key (guild:voiceChannel) -> Currently playing track metadata
-	NowPlayingInfo      = make(map[string]*TrackInfo)
-	NowPlayingInfoMutex sync.Mutex
-
-	// Queue Key (guild:voiceChannel) -> Pause state
-	Paused     = make(map[string]bool)
-	PauseMutex sync.Mutex
-
-	// Queue Key (guild:voiceChannel) -> Volume
-	Volume      = make(map[string]float64)
-	VolumeMutex sync.Mutex
-
-	// Queue Key (guild:voiceChannel) -> Stop channels
-	StopChannels = make(map[string]chan bool)
-	StopMutex    sync.Mutex
-
-	// Queue Key (guild:voiceChannel) -> Pause channels
-	PauseChs     = make(map[string]chan bool)
-	PauseChMutex sync.Mutex
-
-	OpusEncoder *gopus.Encoder
-
 	// Time when the bot started
 	StartTime time.Time
 
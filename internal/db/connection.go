@@ -16,10 +16,12 @@ type DB struct {
 
 // Service wraps DB and provides additional services
 type Service struct {
-	DB             *DB
-	Queries        *Queries
-	CustomCommands *CustomCommandService
-	GuildConfig    *GuildConfigService
+	DB              *DB
+	Queries         *Queries
+	CustomCommands  *CustomCommandService
+	GuildConfig     *GuildConfigService
+	DiscordAccounts *UserDiscordAccountService
+	RadioStations   *RadioStationService
 }
 
 // NewDB creates a new database connection pool and returns a DB instance
@@ -63,10 +65,12 @@ func NewService(ctx context.Context) (*Service, error) {
 	}
 
 	return &Service{
-		DB:             db,
-		Queries:        db.Queries,
-		CustomCommands: NewCustomCommandService(db.Queries),
-		GuildConfig:    NewGuildConfigService(db.Queries),
+		DB:              db,
+		Queries:         db.Queries,
+		CustomCommands:  NewCustomCommandService(db.Queries),
+		GuildConfig:     NewGuildConfigService(db.Queries),
+		DiscordAccounts: NewUserDiscordAccountService(db.Queries),
+		RadioStations:   NewRadioStationService(db.Queries),
 	}, nil
 }
 
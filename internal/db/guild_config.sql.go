@@ -9,6 +9,25 @@ import (
 	"context"
 )
 
+const GetPlaybackConfig = `-- name: GetPlaybackConfig :one
+SELECT guild_id, default_vc, volume
+FROM guild_config
+WHERE guild_id = $1
+`
+
+type GetPlaybackConfigRow struct {
+	GuildID   string  `json:"guild_id"`
+	DefaultVc *string `json:"default_vc"`
+	Volume    int32   `json:"volume"`
+}
+
+func (q *Queries) GetPlaybackConfig(ctx context.Context, guildID string) (*GetPlaybackConfigRow, error) {
+	row := q.db.QueryRow(ctx, GetPlaybackConfig, guildID)
+	var i GetPlaybackConfigRow
+	err := row.Scan(&i.GuildID, &i.DefaultVc, &i.Volume)
+	return &i, err
+}
+
 const GetWelcomeConfig = `-- name: GetWelcomeConfig :one
 SELECT guild_id, welcome_channel_id, welcome_message, welcome_embed_title
 FROM guild_config
@@ -29,6 +48,35 @@ func (q *Queries) GetWelcomeConfig(ctx context.Context, guildID string) (*GetWel
 	return &i, err
 }
 
+const UpsertPlaybackConfig = `-- name: UpsertPlaybackConfig :one
+INSERT INTO guild_config (guild_id, default_vc, volume)
+VALUES ($1, $2, $3)
+ON CONFLICT (guild_id) DO UPDATE
+SET default_vc = EXCLUDED.default_vc,
+    volume = EXCLUDED.volume,
+    updated_at = now()
+RETURNING guild_id, default_vc, volume
+`
+
+type UpsertPlaybackConfigParams struct {
+	GuildID   string  `json:"guild_id"`
+	DefaultVc *string `json:"default_vc"`
+	Volume    int32   `json:"volume"`
+}
+
+type UpsertPlaybackConfigRow struct {
+	GuildID   string  `json:"guild_id"`
+	DefaultVc *string `json:"default_vc"`
+	Volume    int32   `json:"volume"`
+}
+
+func (q *Queries) UpsertPlaybackConfig(ctx context.Context, arg *UpsertPlaybackConfigParams) (*UpsertPlaybackConfigRow, error) {
+	row := q.db.QueryRow(ctx, UpsertPlaybackConfig, arg.GuildID, arg.DefaultVc, arg.Volume)
+	var i UpsertPlaybackConfigRow
+	err := row.Scan(&i.GuildID, &i.DefaultVc, &i.Volume)
+	return &i, err
+}
+
 const UpsertWelcomeConfig = `-- name: UpsertWelcomeConfig :one
 INSERT INTO guild_config (guild_id, welcome_channel_id, welcome_message, welcome_embed_title)
 VALUES ($1, $2, $3, $4)
@@ -18,10 +18,15 @@ var (
 	ErrWelcomeMessageRequired = errors.New("welcome message is required")
 	// ErrWelcomeMessageTooLong indicates the welcome message exceeded the allowed length.
 	ErrWelcomeMessageTooLong = errors.New("welcome message is too long")
+	// ErrVolumeOutOfRange indicates the volume is outside 0-200.
+	ErrVolumeOutOfRange = errors.New("volume must be between 0 and 200")
 )
 
 const maxWelcomeMessageLength = 512
 
+// DefaultVolume matches the guild_config.volume column default (percent).
+const DefaultVolume = 25
+
 // GuildConfigService exposes helpers for guild configuration features.
 type GuildConfigService struct {
 	queries *Queries
@@ -110,3 +115,64 @@ func (s *GuildConfigService) SaveWelcomeSettings(ctx context.Context, guildID, r
 		EmbedTitle: row.WelcomeEmbedTitle,
 	}, nil
 }
+
+// PlaybackSettings represents the guild's playback defaults.
+type PlaybackSettings struct {
+	GuildID   string
+	DefaultVC *string
+	Volume    int // percent, 0-200
+}
+
+// GetPlaybackSettings reads the playback defaults for a guild. Returns the column defaults when unset.
+func (s *GuildConfigService) GetPlaybackSettings(ctx context.Context, guildID string) (*PlaybackSettings, error) {
+	id := strings.TrimSpace(guildID)
+	if id == "" {
+		return nil, ErrGuildIDRequired
+	}
+
+	row, err := s.queries.GetPlaybackConfig(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &PlaybackSettings{GuildID: id, Volume: DefaultVolume}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &PlaybackSettings{
+		GuildID:   row.GuildID,
+		DefaultVC: row.DefaultVc,
+		Volume:    int(row.Volume),
+	}, nil
+}
+
+// SavePlaybackSettings upserts the default voice channel and volume for a guild. A blank channel clears it.
+func (s *GuildConfigService) SavePlaybackSettings(ctx context.Context, guildID, rawDefaultVC string, volume int) (*PlaybackSettings, error) {
+	id := strings.TrimSpace(guildID)
+	if id == "" {
+		return nil, ErrGuildIDRequired
+	}
+
+	if volume < 0 || volume > 200 {
+		return nil, ErrVolumeOutOfRange
+	}
+
+	var defaultVCValue *string
+	if trimmed := strings.TrimSpace(rawDefaultVC); trimmed != "" {
+		defaultVCValue = &trimmed
+	}
+
+	row, err := s.queries.UpsertPlaybackConfig(ctx, &UpsertPlaybackConfigParams{
+		GuildID:   id,
+		DefaultVc: defaultVCValue,
+		Volume:    int32(volume),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PlaybackSettings{
+		GuildID:   row.GuildID,
+		DefaultVC: row.DefaultVc,
+		Volume:    int(row.Volume),
+	}, nil
+}
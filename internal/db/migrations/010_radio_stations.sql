@@ -0,0 +1,16 @@
+-- +goose Up
+create table radio_stations (
+    id uuid primary key default gen_random_uuid(),
+    guild_id text not null,
+    name text not null,
+    url text not null,
+    created_at timestamptz not null default CURRENT_TIMESTAMP
+);
+
+create unique index radio_stations_guild_name_idx
+    on radio_stations (guild_id, lower(name));
+
+-- +goose Down
+drop index if exists radio_stations_guild_name_idx;
+drop table if exists radio_stations;
+
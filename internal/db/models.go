@@ -56,6 +56,14 @@ type QueueHistory struct {
 	AddedAt  pgtype.Timestamptz `json:"added_at"`
 }
 
+type RadioStation struct {
+	ID        pgtype.UUID        `json:"id"`
+	GuildID   string             `json:"guild_id"`
+	Name      string             `json:"name"`
+	Url       string             `json:"url"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
 type RecentlyPlayed struct {
 	ID              pgtype.UUID        `json:"id"`
 	GuildID         string             `json:"guild_id"`
@@ -13,23 +13,30 @@ type Querier interface {
 	CreateCustomCommand(ctx context.Context, arg *CreateCustomCommandParams) (*CustomCommand, error)
 	DeleteBotStatus(ctx context.Context, id string) error
 	DeleteCustomCommand(ctx context.Context, arg *DeleteCustomCommandParams) error
+	DeleteRadioStationByName(ctx context.Context, arg *DeleteRadioStationByNameParams) (int64, error)
 	GetActiveBotStatus(ctx context.Context) (*BotState, error)
 	// Bot state queries
 	GetBotStatus(ctx context.Context, id string) (*BotState, error)
 	GetCustomCommandByName(ctx context.Context, arg *GetCustomCommandByNameParams) (*CustomCommand, error)
 	GetDiscordIdentityByAppUserId(ctx context.Context, appUserID string) (*GetDiscordIdentityByAppUserIdRow, error)
 	GetDiscordIdentityByDiscordUserId(ctx context.Context, discordUserID string) (*GetDiscordIdentityByDiscordUserIdRow, error)
+	GetPlaybackConfig(ctx context.Context, guildID string) (*GetPlaybackConfigRow, error)
+	GetRadioStationByName(ctx context.Context, arg *GetRadioStationByNameParams) (*RadioStation, error)
 	GetWelcomeConfig(ctx context.Context, guildID string) (*GetWelcomeConfigRow, error)
 	InsertRecentlyPlayed(ctx context.Context, arg *InsertRecentlyPlayedParams) error
 	ListAllBotStatuses(ctx context.Context) ([]*BotState, error)
 	// Custom command queries
 	ListCustomCommands(ctx context.Context, guildID string) ([]*CustomCommand, error)
+	// Radio station queries
+	ListRadioStations(ctx context.Context, guildID string) ([]*RadioStation, error)
 	ListRecentlyPlayed(ctx context.Context, arg *ListRecentlyPlayedParams) ([]*RecentlyPlayed, error)
 	TrimRecentlyPlayed(ctx context.Context, arg *TrimRecentlyPlayedParams) error
 	UpdateBotActiveStatus(ctx context.Context, arg *UpdateBotActiveStatusParams) (*BotState, error)
 	UpdateBotActivity(ctx context.Context, arg *UpdateBotActivityParams) (*BotState, error)
 	UpdateBotStatus(ctx context.Context, arg *UpdateBotStatusParams) (*BotState, error)
 	UpdateCustomCommand(ctx context.Context, arg *UpdateCustomCommandParams) (*CustomCommand, error)
+	UpsertPlaybackConfig(ctx context.Context, arg *UpsertPlaybackConfigParams) (*UpsertPlaybackConfigRow, error)
+	UpsertRadioStation(ctx context.Context, arg *UpsertRadioStationParams) (*RadioStation, error)
 	UpsertUserDiscordAccount(ctx context.Context, arg *UpsertUserDiscordAccountParams) error
 	UpsertWelcomeConfig(ctx context.Context, arg *UpsertWelcomeConfigParams) (*UpsertWelcomeConfigRow, error)
 }
@@ -13,3 +13,17 @@ SET welcome_channel_id = EXCLUDED.welcome_channel_id,
     updated_at = now()
 RETURNING guild_id, welcome_channel_id, welcome_message, welcome_embed_title;
 
+
+-- name: GetPlaybackConfig :one
+SELECT guild_id, default_vc, volume
+FROM guild_config
+WHERE guild_id = $1;
+
+-- name: UpsertPlaybackConfig :one
+INSERT INTO guild_config (guild_id, default_vc, volume)
+VALUES ($1, $2, $3)
+ON CONFLICT (guild_id) DO UPDATE
+SET default_vc = EXCLUDED.default_vc,
+    volume = EXCLUDED.volume,
+    updated_at = now()
+RETURNING guild_id, default_vc, volume;
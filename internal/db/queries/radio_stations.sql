@@ -0,0 +1,25 @@
+-- Radio station queries
+
+-- name: ListRadioStations :many
+SELECT id, guild_id, name, url, created_at
+FROM radio_stations
+WHERE guild_id = $1
+ORDER BY lower(name) ASC;
+
+-- name: GetRadioStationByName :one
+SELECT id, guild_id, name, url, created_at
+FROM radio_stations
+WHERE guild_id = sqlc.arg(guild_id) AND lower(name) = lower(sqlc.arg(name))
+LIMIT 1;
+
+-- name: UpsertRadioStation :one
+INSERT INTO radio_stations (guild_id, name, url)
+VALUES ($1, $2, $3)
+ON CONFLICT (guild_id, lower(name)) DO UPDATE
+SET name = EXCLUDED.name,
+    url = EXCLUDED.url
+RETURNING id, guild_id, name, url, created_at;
+
+-- name: DeleteRadioStationByName :execrows
+DELETE FROM radio_stations
+WHERE guild_id = sqlc.arg(guild_id) AND lower(name) = lower(sqlc.arg(name));
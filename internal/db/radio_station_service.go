@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// longest station name, it has to fit a slash command argument comfortably
+const maxRadioStationName = 32
+
+var (
+	// ErrRadioStationNotFound indicates that there is no station for the provided name.
+	ErrRadioStationNotFound = errors.New("radio station not found")
+	// ErrRadioStationNameRequired indicates that the station name was empty after validation.
+	ErrRadioStationNameRequired = errors.New("station name is required")
+	// ErrRadioStationNameTooLong indicates that the station name exceeds maxRadioStationName.
+	ErrRadioStationNameTooLong = errors.New("station name is too long")
+	// ErrRadioStationURLRequired indicates that the station URL was empty after validation.
+	ErrRadioStationURLRequired = errors.New("station url is required")
+)
+
+// RadioStationService wraps sqlc queries for the stations saved per guild.
+type RadioStationService struct {
+	queries *Queries
+}
+
+// NewRadioStationService builds a RadioStationService.
+func NewRadioStationService(queries *Queries) *RadioStationService {
+	return &RadioStationService{queries: queries}
+}
+
+// List returns all stations for a guild ordered alphabetically.
+func (s *RadioStationService) List(ctx context.Context, guildID string) ([]*RadioStation, error) {
+	return s.queries.ListRadioStations(ctx, guildID)
+}
+
+// GetByName finds a station by name using case-insensitive matching.
+func (s *RadioStationService) GetByName(ctx context.Context, guildID, rawName string) (*RadioStation, error) {
+	name := strings.TrimSpace(rawName)
+	if name == "" {
+		return nil, ErrRadioStationNameRequired
+	}
+	station, err := s.queries.GetRadioStationByName(ctx, &GetRadioStationByNameParams{
+		GuildID: guildID,
+		Name:    name,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrRadioStationNotFound
+	}
+	return station, err
+}
+
+// Save stores a station under name, replacing the URL of an existing one.
+func (s *RadioStationService) Save(ctx context.Context, guildID, rawName, rawURL string) (*RadioStation, error) {
+	name := strings.TrimSpace(rawName)
+	if name == "" {
+		return nil, ErrRadioStationNameRequired
+	}
+	if len(name) > maxRadioStationName {
+		return nil, ErrRadioStationNameTooLong
+	}
+
+	url := strings.TrimSpace(rawURL)
+	if url == "" {
+		return nil, ErrRadioStationURLRequired
+	}
+
+	return s.queries.UpsertRadioStation(ctx, &UpsertRadioStationParams{
+		GuildID: guildID,
+		Name:    name,
+		Url:     url,
+	})
+}
+
+// Delete removes a station by name for the guild.
+func (s *RadioStationService) Delete(ctx context.Context, guildID, rawName string) error {
+	name := strings.TrimSpace(rawName)
+	if name == "" {
+		return ErrRadioStationNameRequired
+	}
+
+	deleted, err := s.queries.DeleteRadioStationByName(ctx, &DeleteRadioStationByNameParams{
+		GuildID: guildID,
+		Name:    name,
+	})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrRadioStationNotFound
+	}
+	return nil
+}
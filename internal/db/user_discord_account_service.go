@@ -0,0 +1,172 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrDiscordAccountNotLinked indicates that the app user has no Discord identity on record.
+var ErrDiscordAccountNotLinked = errors.New("discord account not linked")
+
+// how long a looked up identity is served from memory before reading it again.
+// Relinks on other replicas drop it sooner, see SetLinkNotifier.
+const discordIdentityCacheTTL = 5 * time.Minute
+
+// DiscordIdentity is the Discord user behind an app user.
+type DiscordIdentity struct {
+	AppUserID     string
+	DiscordUserID string
+	DisplayTag    string
+}
+
+type cachedDiscordIdentity struct {
+	identity *DiscordIdentity
+	expires  time.Time
+}
+
+// UserDiscordAccountService wraps sqlc queries for the app user to Discord mapping.
+type UserDiscordAccountService struct {
+	queries *Queries
+
+	mu     sync.Mutex
+	cache  map[string]cachedDiscordIdentity
+	onLink func(appUserID string)
+}
+
+// NewUserDiscordAccountService builds a UserDiscordAccountService.
+func NewUserDiscordAccountService(queries *Queries) *UserDiscordAccountService {
+	return &UserDiscordAccountService{
+		queries: queries,
+		cache:   make(map[string]cachedDiscordIdentity),
+	}
+}
+
+// Lookup returns the Discord identity linked to an app user. It never writes, and
+// identities are cached so repeated requests by the same user skip the database.
+func (s *UserDiscordAccountService) Lookup(ctx context.Context, appUserID string) (*DiscordIdentity, error) {
+	appUserID = strings.TrimSpace(appUserID)
+	if appUserID == "" {
+		return nil, ErrDiscordAccountNotLinked
+	}
+
+	if identity := s.cached(appUserID); identity != nil {
+		return identity, nil
+	}
+
+	row, err := s.queries.GetDiscordIdentityByAppUserId(ctx, appUserID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrDiscordAccountNotLinked
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	identity := &DiscordIdentity{
+		AppUserID:     appUserID,
+		DiscordUserID: row.DiscordUserID,
+		DisplayTag:    row.DisplayTag,
+	}
+	s.remember(identity)
+	return identity, nil
+}
+
+// Link maps an app user to a Discord user, creating or updating the mapping only
+// when it differs from the stored one.
+func (s *UserDiscordAccountService) Link(ctx context.Context, appUserID, discordUserID string) (*DiscordIdentity, error) {
+	appUserID = strings.TrimSpace(appUserID)
+	discordUserID = strings.TrimSpace(discordUserID)
+	if appUserID == "" || discordUserID == "" {
+		return nil, ErrDiscordAccountNotLinked
+	}
+
+	row, err := s.queries.GetDiscordIdentityByAppUserId(ctx, appUserID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	if err == nil && row.DiscordUserID == discordUserID {
+		identity := &DiscordIdentity{
+			AppUserID:     appUserID,
+			DiscordUserID: row.DiscordUserID,
+			DisplayTag:    row.DisplayTag,
+		}
+		s.remember(identity)
+		return identity, nil
+	}
+
+	displayTag := discordUserID
+	var avatarHash *string
+	if err == nil {
+		displayTag = row.DisplayTag
+		avatarHash = row.AvatarHash
+	}
+
+	if err := s.queries.UpsertUserDiscordAccount(ctx, &UpsertUserDiscordAccountParams{
+		AppUserID:     appUserID,
+		DiscordUserID: discordUserID,
+		DisplayTag:    displayTag,
+		AvatarHash:    avatarHash,
+	}); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	notify := s.onLink
+	s.mu.Unlock()
+	if notify != nil {
+		notify(appUserID)
+	}
+
+	identity := &DiscordIdentity{
+		AppUserID:     appUserID,
+		DiscordUserID: discordUserID,
+		DisplayTag:    displayTag,
+	}
+	s.remember(identity)
+	return identity, nil
+}
+
+// SetLinkNotifier sets a function called after an app user is linked to a
+// different Discord account, so other replicas can Forget the old one.
+func (s *UserDiscordAccountService) SetLinkNotifier(notify func(appUserID string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.onLink = notify
+}
+
+// Forget drops the cached identity of an app user, the next Lookup reads it again.
+func (s *UserDiscordAccountService) Forget(appUserID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.cache, strings.TrimSpace(appUserID))
+}
+
+func (s *UserDiscordAccountService) cached(appUserID string) *DiscordIdentity {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.cache[appUserID]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(entry.expires) {
+		delete(s.cache, appUserID)
+		return nil
+	}
+	return entry.identity
+}
+
+func (s *UserDiscordAccountService) remember(identity *DiscordIdentity) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cache[identity.AppUserID] = cachedDiscordIdentity{
+		identity: identity,
+		expires:  time.Now().Add(discordIdentityCacheTTL),
+	}
+}
@@ -1,28 +1,27 @@
 package discord
 
-import "github.com/ekkolyth/ekko-bot/internal/context"
+import (
+	"strings"
+
+	"github.com/ekkolyth/ekko-bot/internal/context"
+)
 
 func Help(ctx *context.Context) {
-	prefix := "/"
-	if ctx.GetSourceType() == int(context.SourceTypeMessage) {
-		prefix = "!"
-	}
+	prefix := ctx.CommandPrefix()
 
-	helpMessage := "Commands:\n" +
-		prefix + "ping - Responds with Pong\n" +
-		prefix + "pong - Responds with Ping\n" +
-		prefix + "play <url> - Plays a song from the given URL\n" +
-		prefix + "search <query> - Searches for a song and plays it\n" +
-		prefix + "skip - Skips the current song\n" +
-		prefix + "queue - Shows the current queue\n" +
-		prefix + "stop - Stops playback and clears the queue\n" +
-		prefix + "pause - Pauses playback\n" +
-		prefix + "resume - Resumes playback\n" +
-		prefix + "volume <value> - Sets the volume (0 to 200)\n" +
-		prefix + "currentvolume - Shows the current volume\n" +
-		prefix + "nuke <number> - Deletes the specified number of messages\n" +
-		prefix + "uptime - Shows how long the bot has been running\n" +
-		prefix + "version - Shows a hash-based version of the bot\n" +
-		prefix + "help - Shows this help message\n"
-	ctx.Reply(helpMessage)
+	var helpMessage strings.Builder
+	helpMessage.WriteString("Commands:\n")
+	for _, command := range context.Commands() {
+		if context.DisabledCommands[command.Name] {
+			continue
+		}
+		helpMessage.WriteString(prefix + strings.TrimSpace(command.Name+" "+command.Usage()))
+		helpMessage.WriteString(" - " + command.Description)
+		// aliases only exist for ! commands
+		if prefix == "!" && len(command.Aliases) > 0 {
+			helpMessage.WriteString(" (also !" + strings.Join(command.Aliases, ", !") + ")")
+		}
+		helpMessage.WriteString("\n")
+	}
+	ctx.Reply(helpMessage.String())
 }
@@ -5,40 +5,34 @@ import (
 	"time"
 
 	"github.com/ekkolyth/ekko-bot/internal/context"
-
-	"github.com/bwmarrin/discordgo"
 )
 
 func NukeMessages(ctx *context.Context) {
-	// check if the user has permission to manage messages
-	if !context.HasPermission(ctx, discordgo.PermissionManageMessages) {
-		ctx.Reply("You do not have permission to use this command.")
-		return
-	}
-
-	if ctx.Arguments["count"] == "" {
-		ctx.Reply("Usage: !nuke <number of messages>")
-		return
-	}
 	num, err := strconv.Atoi(ctx.Arguments["count"])
 	if err != nil {
 		ctx.Reply("Invalid number of messages")
 		return
 	}
-	if num < 1 || num > 100 {
-		ctx.Reply("Please specify a number between 1 and 100")
-		return
-	}
 	num++ // Include the command message itself
 
-	messages, err := ctx.GetSession().ChannelMessages(ctx.GetChannelID(), num, "", "", "")
-	if err != nil {
-		ctx.Reply("Error fetching messages")
-		return
-	}
-	for _, message := range messages {
-		ctx.GetSession().ChannelMessageDelete(ctx.GetChannelID(), message.ID)
-		time.Sleep(20 * time.Millisecond) // Rate limit to avoid hitting Discord's API limits
+	// Discord returns at most 100 messages per request, page back from the oldest seen
+	deleted := 0
+	beforeID := ""
+	for deleted < num {
+		messages, err := ctx.GetSession().ChannelMessages(ctx.GetChannelID(), min(num-deleted, 100), beforeID, "", "")
+		if err != nil {
+			ctx.Reply("Error fetching messages")
+			return
+		}
+		if len(messages) == 0 {
+			break
+		}
+		for _, message := range messages {
+			ctx.GetSession().ChannelMessageDelete(ctx.GetChannelID(), message.ID)
+			time.Sleep(20 * time.Millisecond) // Rate limit to avoid hitting Discord's API limits
+		}
+		deleted += len(messages)
+		beforeID = messages[len(messages)-1].ID
 	}
-	ctx.Reply("Nuked " + strconv.Itoa(num-1) + " messages.")
+	ctx.Reply("Nuked " + strconv.Itoa(max(deleted-1, 0)) + " messages.")
 }
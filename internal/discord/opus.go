@@ -0,0 +1,123 @@
+package discord
+
+/*
+#include <stdlib.h>
+
+// libopus itself is compiled into the binary by layeh.com/gopus, these are
+// the declarations from opus.h this shim uses
+typedef struct OpusEncoder OpusEncoder;
+extern OpusEncoder *opus_encoder_create(int Fs, int channels, int application, int *error);
+extern void opus_encoder_destroy(OpusEncoder *st);
+extern int opus_encode(OpusEncoder *st, const short *pcm, int frame_size, unsigned char *data, int max_data_bytes);
+extern int opus_encoder_ctl(OpusEncoder *st, int request, ...);
+
+// request codes from opus_defines.h, cgo can't call the variadic ctl itself
+static int ekko_opus_set(OpusEncoder *st, int request, int value) {
+	return opus_encoder_ctl(st, request, value);
+}
+
+static int ekko_opus_get(OpusEncoder *st, int request, int *value) {
+	return opus_encoder_ctl(st, request, value);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	_ "layeh.com/gopus" // links libopus
+)
+
+const (
+	opusApplicationVoip          = 2048
+	opusApplicationAudio         = 2049
+	opusApplicationLowDelay      = 2051
+	opusSetBitrateRequest        = 4002
+	opusGetBitrateRequest        = 4003
+	opusSetInbandFECRequest      = 4012
+	opusGetInbandFECRequest      = 4013
+	opusSetPacketLossPercRequest = 4014
+	opusGetPacketLossPercRequest = 4015
+)
+
+// OpusEncoder is a libopus encoder. gopus doesn't expose the FEC and packet
+// loss ctls, so the encoder is created and driven through this shim instead.
+// It is not safe for concurrent use.
+type OpusEncoder struct {
+	state *C.OpusEncoder
+}
+
+// newOpusEncoder creates an encoder for application, one of the opusApplication constants
+func newOpusEncoder(sampleRate, channels, application int) (*OpusEncoder, error) {
+	var code C.int
+	state := C.opus_encoder_create(C.int(sampleRate), C.int(channels), C.int(application), &code)
+	if code != 0 || state == nil {
+		return nil, fmt.Errorf("opus encoder create failed: %d", int(code))
+	}
+
+	encoder := &OpusEncoder{state: state}
+	runtime.SetFinalizer(encoder, func(e *OpusEncoder) {
+		C.opus_encoder_destroy(e.state)
+	})
+	return encoder, nil
+}
+
+// Encode encodes one frame of interleaved PCM
+func (e *OpusEncoder) Encode(pcm []int16, frameSize, maxDataBytes int) ([]byte, error) {
+	if len(pcm) == 0 {
+		return nil, fmt.Errorf("opus encode: no pcm")
+	}
+	data := make([]byte, maxDataBytes)
+	n := C.opus_encode(e.state, (*C.short)(unsafe.Pointer(&pcm[0])), C.int(frameSize), (*C.uchar)(unsafe.Pointer(&data[0])), C.int(maxDataBytes))
+	runtime.KeepAlive(e)
+	if n < 0 {
+		return nil, fmt.Errorf("opus encode failed: %d", int(n))
+	}
+	return data[:n], nil
+}
+
+// SetBitrate sets the target bitrate in bits per second
+func (e *OpusEncoder) SetBitrate(bitrate int) error {
+	return e.set(opusSetBitrateRequest, bitrate)
+}
+
+// Bitrate returns the target bitrate in bits per second
+func (e *OpusEncoder) Bitrate() int {
+	return e.get(opusGetBitrateRequest)
+}
+
+// SetInbandFEC toggles in-band forward error correction and sets the packet
+// loss percentage the encoder expects, FEC only kicks in above 0
+func (e *OpusEncoder) SetInbandFEC(enabled bool, packetLoss int) error {
+	fec := 0
+	if enabled {
+		fec = 1
+	}
+	if err := e.set(opusSetInbandFECRequest, fec); err != nil {
+		return err
+	}
+	return e.set(opusSetPacketLossPercRequest, packetLoss)
+}
+
+// InbandFEC reports whether FEC is on and the expected packet loss percentage
+func (e *OpusEncoder) InbandFEC() (bool, int) {
+	return e.get(opusGetInbandFECRequest) == 1, e.get(opusGetPacketLossPercRequest)
+}
+
+func (e *OpusEncoder) set(request, value int) error {
+	code := C.ekko_opus_set(e.state, C.int(request), C.int(value))
+	runtime.KeepAlive(e)
+	if code != 0 {
+		return fmt.Errorf("opus encoder ctl %d failed: %d", request, int(code))
+	}
+	return nil
+}
+
+func (e *OpusEncoder) get(request int) int {
+	var value C.int
+	C.ekko_opus_get(e.state, C.int(request), &value)
+	runtime.KeepAlive(e)
+	return int(value)
+}
@@ -0,0 +1,80 @@
+package discord
+
+import (
+	"sync"
+
+	"github.com/ekkolyth/ekko-bot/internal/config"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+var (
+	// Voice connection -> Opus encoder, one per connection so guilds don't share state
+	encoders      = make(map[*discordgo.VoiceConnection]*OpusEncoder)
+	encodersMutex sync.Mutex
+)
+
+// VoiceEncoder returns the Opus encoder for the voice connection,
+// creating it from the configured settings on first use
+func VoiceEncoder(v *discordgo.VoiceConnection) (*OpusEncoder, error) {
+	encodersMutex.Lock()
+	defer encodersMutex.Unlock()
+
+	if encoder, ok := encoders[v]; ok {
+		return encoder, nil
+	}
+
+	encoder, err := newEncoder(config.Opus())
+	if err != nil {
+		return nil, err
+	}
+	encoders[v] = encoder
+	return encoder, nil
+}
+
+// ReleaseVoiceEncoder drops the encoder for a voice connection
+func ReleaseVoiceEncoder(v *discordgo.VoiceConnection) {
+	encodersMutex.Lock()
+	defer encodersMutex.Unlock()
+
+	delete(encoders, v)
+}
+
+// Disconnect stops speaking, leaves the voice channel and releases the encoder
+func Disconnect(v *discordgo.VoiceConnection) error {
+	v.Speaking(false)
+	ReleaseVoiceEncoder(v)
+	return v.Disconnect()
+}
+
+// build an encoder from the settings
+func newEncoder(settings config.OpusSettings) (*OpusEncoder, error) {
+	encoder, err := newOpusEncoder(config.FrameRate, config.Channels, opusApplication(settings.Application))
+	if err != nil {
+		return nil, err
+	}
+
+	if settings.Bitrate > 0 {
+		if err := encoder.SetBitrate(settings.Bitrate); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := encoder.SetInbandFEC(settings.FEC, settings.PacketLoss); err != nil {
+		return nil, err
+	}
+
+	return encoder, nil
+}
+
+// map the configured application name to the libopus constant
+func opusApplication(name string) int {
+	switch name {
+	case "voip":
+		return opusApplicationVoip
+	case "lowdelay":
+		return opusApplicationLowDelay
+	default:
+		return opusApplicationAudio
+	}
+}
@@ -0,0 +1,33 @@
+package discord
+
+import (
+	"testing"
+
+	"github.com/ekkolyth/ekko-bot/internal/config"
+)
+
+func TestNewEncoderAppliesSettings(t *testing.T) {
+	settings := []config.OpusSettings{
+		{Application: "audio"},
+		{Application: "voip", Bitrate: 64000},
+		{Application: "lowdelay", Bitrate: 128000, FEC: true, PacketLoss: 15},
+	}
+
+	for _, s := range settings {
+		encoder, err := newEncoder(s)
+		if err != nil {
+			t.Fatalf("newEncoder(%+v) error: %v", s, err)
+		}
+		if s.Bitrate != 0 && encoder.Bitrate() != s.Bitrate {
+			t.Errorf("newEncoder(%+v) bitrate = %d; want %d", s, encoder.Bitrate(), s.Bitrate)
+		}
+		if fec, loss := encoder.InbandFEC(); fec != s.FEC || loss != s.PacketLoss {
+			t.Errorf("newEncoder(%+v) FEC = %v, %d%%; want %v, %d%%", s, fec, loss, s.FEC, s.PacketLoss)
+		}
+
+		pcm := make([]int16, config.FrameSize*config.Channels)
+		if _, err := encoder.Encode(pcm, config.FrameSize, config.MaxBytes); err != nil {
+			t.Errorf("Encode with %+v error: %v", s, err)
+		}
+	}
+}
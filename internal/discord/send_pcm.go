@@ -1,29 +1,33 @@
 package discord
 
 import (
+	"time"
+
 	"github.com/ekkolyth/ekko-bot/internal/config"
-	"github.com/ekkolyth/ekko-bot/internal/context"
+	"github.com/ekkolyth/ekko-bot/internal/metrics"
 
 	"github.com/bwmarrin/discordgo"
-	"layeh.com/gopus"
 )
 
+// how long a frame may wait for discordgo to take it before the connection is
+// considered gone
+const opusSendTimeout = time.Second
+
 // SendPCM will receive on the provied channel encode
 // received PCM data into Opus then send that to Discordgo
+// using the voice connection's own encoder
 func SendPCM(v *discordgo.VoiceConnection, pcm <-chan []int16) {
 	if pcm == nil {
 		return
 	}
 
-	var err error
-
-	context.OpusEncoder, err = gopus.NewEncoder(config.FrameRate, config.Channels, gopus.Audio)
-
+	encoder, err := VoiceEncoder(v)
 	if err != nil {
 		OnError("NewEncoder Error", err)
 		return
 	}
 
+	sent := false
 	for {
 
 		// read pcm from chan, exit if channel is closed.
@@ -33,7 +37,7 @@ func SendPCM(v *discordgo.VoiceConnection, pcm <-chan []int16) {
 		}
 
 		// try encoding pcm frame with Opus
-		opus, err := context.OpusEncoder.Encode(recv, config.FrameSize, config.MaxBytes)
+		opus, err := encoder.Encode(recv, config.FrameSize, config.MaxBytes)
 		if err != nil {
 			OnError("Encoding Error", err)
 			return
@@ -44,7 +48,21 @@ func SendPCM(v *discordgo.VoiceConnection, pcm <-chan []int16) {
 			// Sending errors here might not be suited
 			return
 		}
-		// send encoded opus data to the sendOpus channel
-		v.OpusSend <- opus
+		// discordgo drains OpusSend every frame, finding it empty means
+		// we fell behind and the listener heard a gap
+		if sent && len(v.OpusSend) == 0 {
+			metrics.SendUnderruns.Inc()
+		}
+
+		// send encoded opus data to the sendOpus channel. discordgo stops
+		// draining it when the connection closes, don't wait forever then
+		select {
+		case v.OpusSend <- opus:
+		case <-time.After(opusSendTimeout):
+			OnError("Voice connection stopped taking audio", nil)
+			return
+		}
+		metrics.OpusFramesSent.Inc()
+		sent = true
 	}
 }
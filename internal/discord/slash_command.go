@@ -12,62 +12,7 @@ import (
 
 func SetupSlashCommands(s *discordgo.Session) {
 	logging.Info("Setting up slash commands")
-	var minValueAddr float64 = 1.0
-
-	commands := []*discordgo.ApplicationCommand{
-		{Name: "ping", Description: "Replies with Pong"},
-		{Name: "play", Description: "Play a Youtube URL",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "url",
-					Description: "The Youtube URL to play",
-					Required:    true,
-				},
-			},
-		},
-		{Name: "search", Description: "Search for a song to play",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "query",
-					Description: "The search query",
-					Required:    true,
-				},
-			},
-		},
-		{Name: "skip", Description: "Skip the current song"},
-		{Name: "queue", Description: "Show the current queue"},
-		{Name: "stop", Description: "Stop playing and clear the queue"},
-		{Name: "pause", Description: "Pause the current song"},
-		{Name: "resume", Description: "Resume the current song"},
-		{Name: "volume", Description: "Set the volume (0-200)",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionInteger,
-					Name:        "level",
-					Description: "The current volume level (0-200)",
-					Required:    false,
-				},
-			},
-		},
-		{Name: "currentvolume", Description: "Show the current volume"},
-		{Name: "nuke", Description: "Delete a number of messages",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionInteger,
-					Name:        "count",
-					Description: "The number of messages to delete (1-200)",
-					Required:    true,
-					MaxValue:    200.0,
-					MinValue:    &minValueAddr,
-				},
-			},
-		},
-		{Name: "uptime", Description: "Show the bot's uptime"},
-		{Name: "version", Description: "Show the bot's version"},
-		{Name: "help", Description: "Show help information"},
-	}
+	commands := slashCommands()
 
 	// Check if we should refresh commands (delete and recreate all)
 	refreshCommands := strings.ToLower(os.Getenv("REFRESH_COMMANDS"))
@@ -151,3 +96,46 @@ func SetupSlashCommands(s *discordgo.Session) {
 	}
 	logging.Info("Slash commands setup complete.")
 }
+
+// build the slash command definitions from the command registry
+func slashCommands() []*discordgo.ApplicationCommand {
+	var commands []*discordgo.ApplicationCommand
+	for _, command := range context.Commands() {
+		definition := &discordgo.ApplicationCommand{Name: command.Name, Description: command.Description}
+		if command.Permissions != 0 {
+			permissions := command.Permissions
+			definition.DefaultMemberPermissions = &permissions
+		}
+		for _, option := range command.Options {
+			definition.Options = append(definition.Options, slashOption(option))
+		}
+		commands = append(commands, definition)
+	}
+	return commands
+}
+
+func slashOption(option context.Option) *discordgo.ApplicationCommandOption {
+	definition := &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        option.Name,
+		Description: option.Description,
+		Required:    option.Required,
+	}
+	switch option.Type {
+	case context.OptionInteger:
+		definition.Type = discordgo.ApplicationCommandOptionInteger
+	case context.OptionAttachment:
+		definition.Type = discordgo.ApplicationCommandOptionAttachment
+	}
+	if option.MinValue != nil {
+		minValue := float64(*option.MinValue)
+		definition.MinValue = &minValue
+	}
+	if option.MaxValue != nil {
+		definition.MaxValue = float64(*option.MaxValue)
+	}
+	for _, choice := range option.Choices {
+		definition.Choices = append(definition.Choices, &discordgo.ApplicationCommandOptionChoice{Name: choice, Value: choice})
+	}
+	return definition
+}
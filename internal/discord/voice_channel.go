@@ -109,3 +109,28 @@ func userVoiceChannelID(ctx *context.Context) string {
 
 	return ""
 }
+
+// ListenerCount returns the number of users other than bots in a voice channel
+func ListenerCount(s *discordgo.Session, guildID, channelID string) (int, error) {
+	guild, err := s.State.Guild(guildID)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, vs := range guild.VoiceStates {
+		if vs.ChannelID != channelID || (s.State.User != nil && vs.UserID == s.State.User.ID) {
+			continue
+		}
+
+		member := vs.Member
+		if member == nil {
+			member, _ = s.State.Member(guildID, vs.UserID)
+		}
+		if member != nil && member.User != nil && member.User.Bot {
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
@@ -0,0 +1,234 @@
+package ffmpeg
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ekkolyth/ekko-bot/internal/config"
+	"github.com/ekkolyth/ekko-bot/internal/discord"
+	"github.com/ekkolyth/ekko-bot/internal/metrics"
+	"github.com/ekkolyth/ekko-bot/internal/provider"
+)
+
+// Decoder runs ffmpeg over a source and reads PCM frames ahead of playback.
+// Up to config.PrefetchBuffer of audio is decoded before anyone plays it, so
+// a decoder opened while the previous track plays starts without a gap.
+type Decoder struct {
+	source *provider.Stream
+	start  time.Duration
+	cmd    *exec.Cmd
+
+	// closed once ffmpeg's output ends or the decoder is closed
+	frames chan []int16
+	// set before frames is closed when reading failed
+	err error
+
+	mu     sync.Mutex
+	closed bool
+	stop   chan struct{}
+	// closed when ffmpeg has been waited for
+	waitDone chan struct{}
+}
+
+// Decode starts ffmpeg on source from the start offset. The decoder owns the
+// source and closes it with Close.
+func Decode(source *provider.Stream, start time.Duration) (*Decoder, error) {
+	if source.Live {
+		// there is nothing to seek in, playback joins the stream as it is now
+		start = 0
+	}
+
+	input := "pipe:0"
+	if source.URL != "" {
+		input = source.URL
+	}
+
+	var ffmpegArgs []string
+	if strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://") {
+		// only fetch over the network and through the bot, a URL mustn't open
+		// local files, run programs or reach private addresses
+		fetchOptions, err := provider.FetchOptions()
+		if err != nil {
+			source.Close()
+			discord.OnError("ffmpeg fetch proxy Error", err)
+			return nil, err
+		}
+		ffmpegArgs = append(ffmpegArgs, fetchOptions...)
+		// ride out dropped connections instead of ending the track early
+		ffmpegArgs = append(ffmpegArgs,
+			"-reconnect", "1",
+			"-reconnect_streamed", "1",
+			"-reconnect_on_network_error", "1",
+			"-reconnect_delay_max", strconv.Itoa(reconnectDelayMax))
+		if source.Live {
+			// a live server closing the connection is a hiccup, not the end
+			ffmpegArgs = append(ffmpegArgs, "-reconnect_at_eof", "1")
+		}
+	}
+	if start > 0 {
+		// piped input is decoded and discarded up to the offset, URLs may seek
+		ffmpegArgs = append(ffmpegArgs, "-ss", strconv.FormatFloat(start.Seconds(), 'f', 3, 64))
+	}
+	ffmpegArgs = append(ffmpegArgs, "-i", input, "-f", "s16le", "-ar", strconv.Itoa(config.FrameRate), "-ac", strconv.Itoa(config.Channels), "pipe:1")
+	ffmpegCmd := exec.Command("ffmpeg", ffmpegArgs...)
+
+	// Capture stderr for error logging
+	ffmpegStderr := &bytes.Buffer{}
+	ffmpegCmd.Stderr = ffmpegStderr
+
+	// Connect the source output to ffmpeg input
+	var ffmpegIn io.WriteCloser
+	var err error
+	if source.Reader != nil {
+		ffmpegIn, err = ffmpegCmd.StdinPipe()
+		if err != nil {
+			source.Close()
+			discord.OnError("ffmpeg StdinPipe Error", err)
+			return nil, err
+		}
+	}
+
+	ffmpegOut, err := ffmpegCmd.StdoutPipe()
+	if err != nil {
+		source.Close()
+		discord.OnError("ffmpeg StdoutPipe Error", err)
+		return nil, err
+	}
+
+	// Start the ffmpeg process
+	metrics.ProcessSpawnsTotal.WithLabelValues("ffmpeg").Inc()
+	ffmpegStarted := time.Now()
+	if err := ffmpegCmd.Start(); err != nil {
+		metrics.ProcessFailuresTotal.WithLabelValues("ffmpeg").Inc()
+		source.Close()
+		discord.OnError("ffmpeg Start Error", err)
+		return nil, err
+	}
+
+	// one frame is 20ms, buffer at least what the sender holds
+	buffered := int(config.PrefetchBuffer() * time.Duration(config.FrameRate) / time.Duration(config.FrameSize) / time.Second)
+	if buffered < 2 {
+		buffered = 2
+	}
+
+	decoder := &Decoder{
+		source:   source,
+		start:    start,
+		cmd:      ffmpegCmd,
+		frames:   make(chan []int16, buffered),
+		stop:     make(chan struct{}),
+		waitDone: make(chan struct{}),
+	}
+
+	// Monitor ffmpeg process for errors in background
+	go func() {
+		defer close(decoder.waitDone)
+		err := ffmpegCmd.Wait()
+		// processes killed by Close exit with an error too, that isn't a failure
+		if decoder.isClosed() {
+			err = nil
+		}
+		metrics.ObserveProcess("ffmpeg", ffmpegStarted, err)
+		if err != nil {
+			if ffmpegStderr.Len() > 0 {
+				discord.OnError("ffmpeg failed: "+ffmpegStderr.String(), err)
+			} else {
+				discord.OnError("ffmpeg process exited with error", err)
+			}
+		}
+	}()
+
+	// Pipe the source output to ffmpeg input
+	if ffmpegIn != nil {
+		go func() {
+			_, err := io.Copy(ffmpegIn, source.Reader)
+			if err != nil && !decoder.isClosed() {
+				discord.OnError("Error copying source output to ffmpeg input", err)
+			}
+			ffmpegIn.Close() // Important: close the pipe when done
+		}()
+	}
+
+	go decoder.read(ffmpegOut)
+	return decoder, nil
+}
+
+// Frames returns the decoded audio, closed when the track ends
+func (d *Decoder) Frames() <-chan []int16 {
+	return d.frames
+}
+
+// Err returns why decoding stopped early, valid once Frames is closed
+func (d *Decoder) Err() error {
+	return d.err
+}
+
+// Start returns the offset the audio begins at
+func (d *Decoder) Start() time.Duration {
+	return d.start
+}
+
+// Live reports whether the source is a live stream without an end
+func (d *Decoder) Live() bool {
+	return d.source.Live
+}
+
+// Close stops ffmpeg and the source, buffered audio is dropped
+func (d *Decoder) Close() {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.stop)
+		d.source.Close()
+		if d.cmd.Process != nil {
+			d.cmd.Process.Kill()
+		}
+	}
+	d.mu.Unlock()
+
+	// Wait for Wait() to complete to avoid waitid errors
+	select {
+	case <-d.waitDone:
+	case <-time.After(1 * time.Second):
+	}
+}
+
+func (d *Decoder) isClosed() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.closed
+}
+
+// read whole frames from ffmpeg until it ends, blocking while the buffer is full
+func (d *Decoder) read(output io.Reader) {
+	defer close(d.frames)
+
+	ffmpegbuf := bufio.NewReaderSize(output, config.FfmpegBufferSize)
+	for {
+		audiobuf := make([]int16, config.FrameSize*config.Channels)
+		err := binary.Read(ffmpegbuf, binary.LittleEndian, &audiobuf)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return
+		}
+		if err != nil {
+			if !d.isClosed() {
+				discord.OnError("Error reading from ffmpeg stdout", err)
+				d.err = err
+			}
+			return
+		}
+
+		select {
+		case d.frames <- audiobuf:
+		case <-d.stop:
+			return
+		}
+	}
+}
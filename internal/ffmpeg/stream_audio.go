@@ -1,190 +1,73 @@
 package ffmpeg
 
 import (
-	"bufio"
-	"bytes"
-	"encoding/binary"
-	"io"
-	"os/exec"
-	"strconv"
-	"sync"
+	"errors"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 
-	"github.com/ekkolyth/ekko-bot/internal/api/httpx"
 	"github.com/ekkolyth/ekko-bot/internal/config"
 	"github.com/ekkolyth/ekko-bot/internal/context"
 	"github.com/ekkolyth/ekko-bot/internal/discord"
 )
 
-// Discord voice server/channel.  voice websocket and udp socket
-// must already be setup before this will work.
-func StreamAudio(v *discordgo.VoiceConnection, url string, queueKey string, stop <-chan bool, pauseCh <-chan bool) {
-
-	if !httpx.IsValidURL(url) {
-		discord.OnError("Invalid URL"+url, nil)
-		return
-	}
-
-	// Create the yt-dlp command to download only the audio with best quality
-	ytDlpCmd := exec.Command("yt-dlp",
-		"-f", "bestaudio",
-		"--no-playlist",
-		"-o", "-",
-		url) // Get only audio, best quality
-
-	// Capture stderr for error logging
-	ytDlpStderr := &bytes.Buffer{}
-	ytDlpCmd.Stderr = ytDlpStderr
-
-	ffmpegCmd := exec.Command("ffmpeg", "-i", "pipe:0", "-f", "s16le", "-ar", strconv.Itoa(config.FrameRate), "-ac", strconv.Itoa(config.Channels), "pipe:1")
-
-	// Capture stderr for error logging
-	ffmpegStderr := &bytes.Buffer{}
-	ffmpegCmd.Stderr = ffmpegStderr
-
-	// Track if processes have been cleaned up to avoid double Wait()
-	var processesCleaned bool
-	var cleanupMutex sync.Mutex
-
-	cleanupProcesses := func() {
-		cleanupMutex.Lock()
-		defer cleanupMutex.Unlock()
-		if processesCleaned {
-			return
-		}
-		processesCleaned = true
-
-		if ytDlpCmd.Process != nil {
-			ytDlpCmd.Process.Kill()
-		}
-		if ffmpegCmd.Process != nil {
-			ffmpegCmd.Process.Kill()
-		}
-	}
-
-	// Setup proper cleanup to ensure processes terminate
-	defer cleanupProcesses()
-
-	// Connect yt-dlp output to ffmpeg input
-	ytDlpOut, err := ytDlpCmd.StdoutPipe()
-	if err != nil {
-		discord.OnError("yt-dlp StdoutPipe Error", err)
-		return
-	}
+// longest wait in seconds between ffmpeg's reconnect attempts to a URL
+const reconnectDelayMax = 30
 
-	ffmpegIn, err := ffmpegCmd.StdinPipe()
-	if err != nil {
-		discord.OnError("ffmpeg StdinPipe Error", err)
-		return
-	}
+// ErrNoAudio is returned when the stream ended without producing any audio
+var ErrNoAudio = errors.New("stream produced no audio")
 
-	ffmpegOut, err := ffmpegCmd.StdoutPipe()
-	if err != nil {
-		discord.OnError("ffmpeg StdoutPipe Error", err)
-		return
-	}
-
-	// Start the yt-dlp and ffmpeg processes
-	err = ytDlpCmd.Start()
-	if err != nil {
-		discord.OnError("yt-dlp Start Error", err)
-		return
-	}
-
-	err = ffmpegCmd.Start()
-	if err != nil {
-		discord.OnError("ffmpeg Start Error", err)
-		// Check for stderr output from yt-dlp
-		if ytDlpStderr.Len() > 0 {
-			discord.OnError("yt-dlp stderr: "+ytDlpStderr.String(), nil)
-		}
-		return
+// Discord voice server/channel.  voice websocket and udp socket
+// must already be setup before this will work.
+// The decoder's audio is sent from its start offset and the decoder is closed when playback ends.
+// Playback follows the player's pause and volume state and ends when interrupt is closed.
+// Returns an error if decoding failed or produced no audio.
+func StreamAudio(v *discordgo.VoiceConnection, decoder *Decoder, player *context.Player, interrupt <-chan struct{}) error {
+	finished := make(chan struct{})
+	defer close(finished)
+
+	// Handle stopping processes if needed. Live streams only end when
+	// interrupted, a nil channel never fires.
+	var fallback <-chan time.Time
+	if !decoder.Live() {
+		fallback = time.After(3 * time.Hour) // Fallback timeout
 	}
-
-	// Monitor yt-dlp process for errors in background
-	ytDlpWaitDone := make(chan bool, 1)
-	go func() {
-		defer func() { ytDlpWaitDone <- true }()
-		if err := ytDlpCmd.Wait(); err != nil {
-			if ytDlpStderr.Len() > 0 {
-				discord.OnError("yt-dlp failed: "+ytDlpStderr.String(), err)
-			} else {
-				discord.OnError("yt-dlp process exited with error", err)
-			}
-		}
-	}()
-
-	// Monitor ffmpeg process for errors in background
-	ffmpegWaitDone := make(chan bool, 1)
-	go func() {
-		defer func() { ffmpegWaitDone <- true }()
-		if err := ffmpegCmd.Wait(); err != nil {
-			if ffmpegStderr.Len() > 0 {
-				discord.OnError("ffmpeg failed: "+ffmpegStderr.String(), err)
-			} else {
-				discord.OnError("ffmpeg process exited with error", err)
-			}
-		}
-	}()
-
-	// Pipe yt-dlp output to ffmpeg input
-	go func() {
-		_, err := io.Copy(ffmpegIn, ytDlpOut)
-		if err != nil {
-			discord.OnError("Error copying yt-dlp output to ffmpeg input", err)
-		}
-		ffmpegIn.Close() // Important: close the pipe when done
-	}()
-
-	// Set up reading from ffmpeg output
-	ffmpegbuf := bufio.NewReaderSize(ffmpegOut, config.FfmpegBufferSize)
-
-	// Handle stopping processes if needed
 	go func() {
 		select {
-		case <-stop:
-			cleanupProcesses()
-		case <-time.After(3 * time.Hour): // Fallback timeout
-			cleanupProcesses()
+		case <-interrupt:
+			decoder.Close()
+		case <-fallback:
+			decoder.Close()
+		case <-finished:
 		}
 	}()
 
-	// Make sure voice connection is ready before starting
-	time.Sleep(100 * time.Millisecond)
-
 	// Set voice speaking status
-	err = v.Speaking(true)
+	err := v.Speaking(true)
 	if err != nil {
 		discord.OnError("Couldn't set speaking", err)
 	}
 
 	// Stop speaking when done (voice overlay feature)
 	defer func() {
-		cleanupProcesses()
-		// Wait for Wait() calls to complete to avoid waitid errors
-		select {
-		case <-ytDlpWaitDone:
-		case <-time.After(1 * time.Second):
-		}
-		select {
-		case <-ffmpegWaitDone:
-		case <-time.After(1 * time.Second):
-		}
+		decoder.Close()
 		err := v.Speaking(false)
 		if err != nil {
 			discord.OnError("Couldn't stop speaking", err)
 		}
 	}()
 
+	// the sender is waited for on every return, so the next track's sender
+	// never shares the connection's encoder or interleaves frames with it
 	send := make(chan []int16, 2)
-	defer close(send)
-
-	closeCh := make(chan bool)
+	senderDone := make(chan struct{})
 	go func() {
+		defer close(senderDone)
 		discord.SendPCM(v, send)
-		closeCh <- true
+	}()
+	defer func() {
+		close(send)
+		<-senderDone
 	}()
 
 	// Add a minimum playback timer for very short clips
@@ -193,64 +76,45 @@ func StreamAudio(v *discordgo.VoiceConnection, url string, queueKey string, stop
 
 	dataReceived := false
 
-	// Track pause state
-	isPaused := false
+	// playback position is derived from the number of frames sent
+	frameDuration := time.Second * time.Duration(config.FrameSize) / time.Duration(config.FrameRate)
+	framesSent := 0
+	start := decoder.Start()
+	player.SetPosition(start)
 
 	// Stream audio from ffmpeg
 	for {
-		// Check pause channel
-		select {
-		case newState := <-pauseCh:
-			isPaused = newState
-			continue
-		default:
-			// No new pause state, continue
+		// Block while paused, bail out if the track is interrupted
+		if !player.WaitWhilePaused(interrupt) {
+			return nil
 		}
 
-		// If paused, wait and check again
-		if isPaused {
-			time.Sleep(100 * time.Millisecond)
-			continue
+		var audiobuf []int16
+		var ok bool
+		select {
+		case audiobuf, ok = <-decoder.Frames():
+		case <-interrupt:
+			return nil
 		}
-		audiobuf := make([]int16, config.FrameSize*config.Channels)
-
-		// Process audio normally
-		err = binary.Read(ffmpegbuf, binary.LittleEndian, &audiobuf)
-		if err == io.EOF || err == io.ErrUnexpectedEOF {
+		if !ok {
+			if err := decoder.Err(); err != nil {
+				return err
+			}
 			if !dataReceived {
 				// If we never got any data, wait a bit more
 				select {
 				case <-minPlayTimer.C:
-					return
-				case <-closeCh:
-					return
+				case <-senderDone:
 				}
+				return ErrNoAudio
 			}
-			return
-		}
-		if err != nil {
-			discord.OnError("Error reading from ffmpeg stdout", err)
-			return
+			return nil
 		}
 
 		dataReceived = true
 
-		// Apply volume adjustment with Redis-backed defaults
-		context.VolumeMutex.Lock()
-		currentVolume, ok := context.Volume[queueKey]
-		context.VolumeMutex.Unlock()
-
-		if !ok {
-			currentVolume = 1.0
-			if store := context.GetQueueStore(); store != nil {
-				if storedValue, err := store.GetVolume(queueKey); err == nil {
-					currentVolume = storedValue
-				}
-			}
-			context.VolumeMutex.Lock()
-			context.Volume[queueKey] = currentVolume
-			context.VolumeMutex.Unlock()
-		}
+		// Apply volume adjustment
+		currentVolume := player.Volume()
 
 		for i := range audiobuf {
 			// Calculate new value and clamp to int16 range to prevent distortion
@@ -266,8 +130,10 @@ func StreamAudio(v *discordgo.VoiceConnection, url string, queueKey string, stop
 		// Send audio data to channel
 		select {
 		case send <- audiobuf:
-		case <-closeCh:
-			return
+			framesSent++
+			player.SetPosition(start + time.Duration(framesSent)*frameDuration)
+		case <-senderDone:
+			return nil
 		}
 	}
 }
@@ -0,0 +1,52 @@
+package ffmpeg
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/ekkolyth/ekko-bot/internal/config"
+	"github.com/ekkolyth/ekko-bot/internal/context"
+	"github.com/ekkolyth/ekko-bot/internal/discord"
+	"github.com/ekkolyth/ekko-bot/internal/provider"
+)
+
+// decoder already holding frames, as if ffmpeg had finished
+func decodedFrames(count int) *Decoder {
+	frames := make(chan []int16, count)
+	for range count {
+		frames <- make([]int16, config.FrameSize*config.Channels)
+	}
+	close(frames)
+
+	waitDone := make(chan struct{})
+	close(waitDone)
+	return &Decoder{
+		source:   &provider.Stream{},
+		cmd:      &exec.Cmd{},
+		frames:   frames,
+		stop:     make(chan struct{}),
+		waitDone: waitDone,
+	}
+}
+
+func TestStreamAudioWaitsForSender(t *testing.T) {
+	v := &discordgo.VoiceConnection{Ready: true, OpusSend: make(chan []byte, 64)}
+	defer discord.ReleaseVoiceEncoder(v)
+	player := context.NewPlayer("guild:stream", 1.0)
+
+	for track := range 3 {
+		interrupt := player.StartTrack(&context.TrackInfo{URL: "https://example.com/song.mp3"})
+		if err := StreamAudio(v, decodedFrames(10), player, interrupt); err != nil {
+			t.Fatalf("StreamAudio() track %d error = %v", track+1, err)
+		}
+		// every frame is on the connection before the next track starts
+		if got := len(v.OpusSend); got != 10 {
+			t.Fatalf("track %d left %d frames on the connection; want 10", track+1, got)
+		}
+		for len(v.OpusSend) > 0 {
+			<-v.OpusSend
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ekkolyth/ekko-bot/internal/bus"
+	appctx "github.com/ekkolyth/ekko-bot/internal/context"
+	"github.com/ekkolyth/ekko-bot/internal/discord"
+	"github.com/ekkolyth/ekko-bot/internal/music"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+var errNothingPlaying = errors.New("nothing is playing")
+
+// HandleBusCommand returns the handler executing API commands against the bot's players
+func HandleBusCommand(s *discordgo.Session) bus.Handler {
+	return func(cmd bus.Command) ([]string, error) {
+		if cmd.GuildID == "" || cmd.VoiceChannelID == "" {
+			return nil, errors.New("missing guild or voice channel")
+		}
+
+		replies := &replyCollector{}
+		ctx := &appctx.Context{
+			SourceType:             appctx.SourceTypeWeb,
+			Session:                s,
+			GuildID:                cmd.GuildID,
+			VoiceChannelID:         cmd.VoiceChannelID,
+			RequesterDiscordUserID: cmd.UserID,
+			RequesterTag:           cmd.UserTag,
+			Arguments:              make(map[string]string),
+			ArgumentsRaw:           make(map[string]any),
+			OnWebReply:             replies.add,
+			CommandName:            string(cmd.Type),
+			RequestID:              cmd.RequestID,
+		}
+
+		err := runBusCommand(ctx, cmd)
+		return replies.close(), err
+	}
+}
+
+func runBusCommand(ctx *appctx.Context, cmd bus.Command) error {
+	queueKey := appctx.QueueKey(cmd.GuildID, cmd.VoiceChannelID)
+
+	switch cmd.Type {
+	case bus.CommandEnqueue:
+		if cmd.URL == "" {
+			return errors.New("missing url")
+		}
+		ctx.Arguments["url"] = cmd.URL
+		return enqueue(ctx, cmd.URL)
+	case bus.CommandPlay:
+		if player, ok := appctx.LookupPlayer(queueKey); ok && player.State().Paused {
+			music.PauseSong(ctx) // Toggle pause off
+		} else {
+			music.ProcessQueue(ctx)
+		}
+	case bus.CommandPause:
+		if !isPlaying(queueKey) {
+			return errNothingPlaying
+		}
+		music.PauseSong(ctx)
+	case bus.CommandSkip:
+		if !isPlaying(queueKey) {
+			return errNothingPlaying
+		}
+		music.SkipSong(ctx)
+	case bus.CommandStop:
+		if _, err := discord.GetVoiceConnection(ctx); err != nil {
+			return errors.New("not in a voice channel")
+		}
+		music.StopSong(ctx)
+	case bus.CommandVolume:
+		if cmd.Volume < 0 || cmd.Volume > 200 {
+			return errors.New("volume must be between 0 and 200")
+		}
+		ctx.Arguments["level"] = strconv.FormatFloat(cmd.Volume, 'f', -1, 64)
+		music.SetVolume(ctx)
+	case bus.CommandSeek:
+		if !isPlaying(queueKey) {
+			return errNothingPlaying
+		}
+		if cmd.PositionMS < 0 {
+			return errors.New("invalid position")
+		}
+		return music.SeekTo(ctx, time.Duration(cmd.PositionMS)*time.Millisecond)
+	default:
+		return fmt.Errorf("unknown command %q", cmd.Type)
+	}
+	return nil
+}
+
+// enqueueBudget is how long an enqueue may run before the API is answered,
+// well inside bus.ReplyTimeout. Expanding a Spotify or YouTube playlist and
+// checking a slow server can take longer and finish in the background.
+const enqueueBudget = bus.ReplyTimeout / 2
+
+// add url to the queue, answering with its error if it fails within
+// enqueueBudget and acknowledging it otherwise
+func enqueue(ctx *appctx.Context, url string) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- music.AddSong(ctx, false, url)
+	}()
+
+	timer := time.NewTimer(enqueueBudget)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		ctx.Reply("Still adding, the queue updates once it's done.")
+		return nil
+	}
+}
+
+// report whether the queue has a track playing in this process
+func isPlaying(queueKey string) bool {
+	player, ok := appctx.LookupPlayer(queueKey)
+	return ok && player.State().NowPlaying != nil
+}
+
+// collect web replies until the command finishes, later replies
+// (e.g. "Now playing" from the queue loop) are dropped
+type replyCollector struct {
+	mu       sync.Mutex
+	messages []string
+	closed   bool
+}
+
+func (c *replyCollector) add(message string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		c.messages = append(c.messages, message)
+	}
+}
+
+func (c *replyCollector) close() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	return c.messages
+}
@@ -4,12 +4,10 @@ import (
 	stdctx "context"
 	"errors"
 
-	"github.com/ekkolyth/ekko-bot/internal/api/httpx"
 	"github.com/ekkolyth/ekko-bot/internal/context"
 	appdb "github.com/ekkolyth/ekko-bot/internal/db"
 	"github.com/ekkolyth/ekko-bot/internal/discord"
-	"github.com/ekkolyth/ekko-bot/internal/logging"
-	"github.com/ekkolyth/ekko-bot/internal/music"
+	"github.com/ekkolyth/ekko-bot/internal/metrics"
 )
 
 var customCommandService *appdb.CustomCommandService
@@ -20,46 +18,50 @@ func SetCustomCommandService(service *appdb.CustomCommandService) {
 
 // CommandSelector forwards commands to the appropriate handlers
 func CommandSelector(ctx *context.Context) {
+	// custom and unknown names are user input, keep them out of the metric labels
+	label := ctx.CommandName
+	defer func() {
+		if r := recover(); r != nil {
+			ctx.Logger().Error("Command panicked", "panic", r)
+			metrics.CommandErrorsTotal.WithLabelValues(label, "panic").Inc()
+			ctx.Reply("Something went wrong running that command.")
+		}
+		metrics.CommandsTotal.WithLabelValues(label, ctx.SourceType.String()).Inc()
+	}()
+
 	if context.DisabledCommands[ctx.CommandName] {
+		metrics.CommandErrorsTotal.WithLabelValues(label, "disabled").Inc()
 		ctx.Reply("This command has been disabled.")
 		return
 	}
 
-	switch ctx.CommandName {
-	case "ping":
-		discord.Ping(ctx)
-	case "pong":
-		discord.Ping(ctx)
-	case "play":
-		music.AddSong(ctx, false) // false as in not a search
-	case "search":
-		music.AddSong(ctx, true) // true as in search for a song
-	case "skip":
-		music.SkipSong(ctx)
-	case "queue":
-		music.ShowQueue(ctx)
-	case "stop":
-		music.StopSong(ctx)
-	case "pause", "resume":
-		music.PauseSong(ctx)
-	case "volume":
-		music.SetVolume(ctx)
-	case "currentvolume":
-		music.CurrentVolume(ctx)
-	case "nuke": // delete n messages
-		discord.NukeMessages(ctx)
-	case "uptime":
-		httpx.Uptime(ctx)
-	case "version":
-		httpx.Version(ctx)
-	case "help":
-		discord.Help(ctx)
-	default:
+	command, ok := context.LookupCommand(ctx.CommandName)
+	if !ok {
 		if runCustomCommand(ctx) {
+			label = "custom"
 			return
 		}
+		label = "unknown"
+		metrics.CommandErrorsTotal.WithLabelValues(label, "unknown").Inc()
 		discord.Unknown(ctx)
+		return
+	}
+
+	// web and system actions are authorised before they reach the bot
+	fromDiscord := ctx.SourceType == context.SourceTypeInteraction || ctx.SourceType == context.SourceTypeMessage
+	if command.Permissions != 0 && fromDiscord && !context.HasPermission(ctx, command.Permissions) {
+		metrics.CommandErrorsTotal.WithLabelValues(label, "forbidden").Inc()
+		ctx.Reply("You do not have permission to use this command.")
+		return
 	}
+
+	if message, valid := command.Validate(ctx.CommandPrefix(), ctx.Arguments); !valid {
+		metrics.CommandErrorsTotal.WithLabelValues(label, "invalid").Inc()
+		ctx.Reply(message)
+		return
+	}
+
+	command.Handler(ctx)
 }
 
 func runCustomCommand(ctx *context.Context) bool {
@@ -77,7 +79,7 @@ func runCustomCommand(ctx *context.Context) bool {
 		if errors.Is(err, appdb.ErrCustomCommandNotFound) || errors.Is(err, appdb.ErrCustomCommandNameRequired) {
 			return false
 		}
-		logging.Error("Custom command lookup failed for " + ctx.CommandName + ": " + err.Error())
+		ctx.Logger().Error("Custom command lookup failed", "error", err)
 		return false
 	}
 
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/ekkolyth/ekko-bot/internal/api/httpx"
+	"github.com/ekkolyth/ekko-bot/internal/context"
+	"github.com/ekkolyth/ekko-bot/internal/discord"
+	"github.com/ekkolyth/ekko-bot/internal/music"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func init() {
+	context.RegisterCommands(builtinCommands()...)
+}
+
+// builtinCommands declares every built-in command. Slash definitions, ! parsing,
+// argument standardisation and help are generated from this list.
+func builtinCommands() []*context.Command {
+	return []*context.Command{
+		{Name: "ping", Aliases: []string{"pong"}, Description: "Replies with Pong", Handler: discord.Ping},
+		{Name: "play", Description: "Play a link, playlist or audio file",
+			Options: []context.Option{
+				{
+					Name:        "url",
+					Description: "A YouTube, Spotify, SoundCloud, Bandcamp or audio file link",
+					Aliases:     []string{"song"}, // name used before the option was renamed
+					Normalize: func(value string) string {
+						// Strip any leading "/play " prefix that Discord might include
+						trimmed, _ := strings.CutPrefix(value, "/play ")
+						return trimmed
+					},
+				},
+				{Name: "file", Description: "An mp3, ogg, flac or wav file to play", Type: context.OptionAttachment},
+			},
+			Handler: func(ctx *context.Context) { music.AddSong(ctx, false) }, // false as in not a search
+		},
+		{Name: "search", Description: "Search for a song to play",
+			Options: []context.Option{{Name: "query", Description: "The search query", Required: true}},
+			Handler: func(ctx *context.Context) { music.AddSong(ctx, true) }, // true as in search for a song
+		},
+		{Name: "radio", Description: "Play a saved radio station",
+			Options: []context.Option{{Name: "name", Description: "The station's name, see stations", Required: true}},
+			Handler: music.PlayStation,
+		},
+		{Name: "stations", Description: "List the saved radio stations", Handler: music.ListStations},
+		{Name: "savestation", Description: "Save a radio or live stream link under a name",
+			Options: []context.Option{
+				{Name: "name", Description: "Name to play it by (up to 32 characters)", Required: true},
+				{Name: "url", Description: "The stream link", Required: true},
+			},
+			Permissions: discordgo.PermissionManageGuild,
+			Handler:     music.SaveStation,
+		},
+		{Name: "deletestation", Description: "Delete a saved radio station",
+			Options:     []context.Option{{Name: "name", Description: "The station's name", Required: true}},
+			Permissions: discordgo.PermissionManageGuild,
+			Handler:     music.DeleteStation,
+		},
+		{Name: "skip", Description: "Skip the current song", Handler: music.SkipSong},
+		{Name: "queue", Description: "Show the current queue", Handler: music.ShowQueue},
+		{Name: "nowplaying", Description: "Show the current song and its progress", Handler: music.NowPlaying},
+		{Name: "shuffle", Description: "Shuffle the queued songs", Handler: music.ShuffleQueue},
+		{Name: "loop", Description: "Set the loop mode",
+			Options: []context.Option{{
+				Name:        "mode",
+				Description: "What to loop (leave empty to show the current mode)",
+				Choices:     []string{"off", "track", "queue"},
+			}},
+			Handler: music.SetLoopMode,
+		},
+		{Name: "move", Description: "Move a song to a new position in the queue",
+			Options: []context.Option{
+				{Name: "from", Description: "Current position of the song", Type: context.OptionInteger, Required: true, MinValue: intPtr(1)},
+				{Name: "to", Description: "New position of the song", Type: context.OptionInteger, Required: true, MinValue: intPtr(1)},
+			},
+			Handler: music.MoveTrack,
+		},
+		{Name: "playnext", Description: "Move a song to the front of the queue",
+			Options: []context.Option{
+				{Name: "position", Description: "Current position of the song", Type: context.OptionInteger, Required: true, MinValue: intPtr(1)},
+			},
+			Handler: music.PlayNext,
+		},
+		{Name: "seek", Description: "Restart the current song from a position",
+			Options: []context.Option{{Name: "position", Description: "Position to seek to (mm:ss)", Required: true}},
+			Handler: music.SeekSong,
+		},
+		{Name: "stop", Description: "Stop playing and clear the queue", Handler: music.StopSong},
+		{Name: "pause", Description: "Pause the current song", Handler: music.PauseSong},
+		{Name: "resume", Description: "Resume the current song", Handler: music.PauseSong},
+		{Name: "volume", Description: "Set the volume (0-200)",
+			Options: []context.Option{
+				{Name: "level", Description: "The new volume level (0-200)", Type: context.OptionInteger, MinValue: intPtr(0), MaxValue: intPtr(200)},
+			},
+			Handler: music.SetVolume,
+		},
+		{Name: "currentvolume", Description: "Show the current volume", Handler: music.CurrentVolume},
+		{Name: "nuke", Description: "Delete a number of messages",
+			Options: []context.Option{
+				{Name: "count", Description: "The number of messages to delete (1-200)", Type: context.OptionInteger, Required: true, MinValue: intPtr(1), MaxValue: intPtr(200)},
+			},
+			Permissions: discordgo.PermissionManageMessages,
+			Handler:     discord.NukeMessages,
+		},
+		{Name: "uptime", Description: "Show the bot's uptime", Permissions: discordgo.PermissionAdministrator, Handler: httpx.Uptime},
+		{Name: "version", Description: "Show the bot's version", Permissions: discordgo.PermissionAdministrator, Handler: httpx.Version},
+		{Name: "help", Description: "Show help information", Handler: discord.Help},
+	}
+}
+
+func intPtr(value int) *int {
+	return &value
+}
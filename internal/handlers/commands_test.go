@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"regexp"
+	"testing"
+)
+
+// Discord rejects slash command and option names outside this pattern
+var slashName = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+func TestBuiltinCommandsAreValidSlashCommands(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, command := range builtinCommands() {
+		if !slashName.MatchString(command.Name) {
+			t.Errorf("%s: name isn't a valid slash command name", command.Name)
+		}
+		if len(command.Description) == 0 || len(command.Description) > 100 {
+			t.Errorf("%s: description is %d characters; want 1-100", command.Name, len(command.Description))
+		}
+		if command.Handler == nil {
+			t.Errorf("%s: no handler", command.Name)
+		}
+		for _, name := range append([]string{command.Name}, command.Aliases...) {
+			if seen[name] {
+				t.Errorf("%s: name or alias %q is used twice", command.Name, name)
+			}
+			seen[name] = true
+		}
+
+		optional := false
+		for _, option := range command.Options {
+			if !slashName.MatchString(option.Name) {
+				t.Errorf("%s: option %q isn't a valid option name", command.Name, option.Name)
+			}
+			if len(option.Description) == 0 || len(option.Description) > 100 {
+				t.Errorf("%s %s: description is %d characters; want 1-100", command.Name, option.Name, len(option.Description))
+			}
+			if option.Required && optional {
+				t.Errorf("%s %s: required option after an optional one", command.Name, option.Name)
+			}
+			optional = optional || !option.Required
+			if option.MinValue != nil && option.MaxValue != nil && *option.MinValue > *option.MaxValue {
+				t.Errorf("%s %s: MinValue %d above MaxValue %d", command.Name, option.Name, *option.MinValue, *option.MaxValue)
+			}
+		}
+	}
+}
+
+func TestNukeAllowsUpTo200Messages(t *testing.T) {
+	for _, command := range builtinCommands() {
+		if command.Name != "nuke" {
+			continue
+		}
+		if _, ok := command.Validate("/", map[string]string{"count": "200"}); !ok {
+			t.Error("Validate(count=200) = false; want true")
+		}
+		if message, ok := command.Validate("/", map[string]string{"count": "201"}); ok || message != "count must be between 1 and 200" {
+			t.Errorf("Validate(count=201) = %q, %v; want range error", message, ok)
+		}
+		return
+	}
+	t.Fatal("no nuke command")
+}
@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"github.com/ekkolyth/ekko-bot/internal/music"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func HandleGuildCreate(s *discordgo.Session, event *discordgo.GuildCreate) {
+	if event == nil || event.Guild == nil {
+		return
+	}
+
+	// the guild's voice states are in the state cache now, resume what a restart interrupted
+	music.ResumeGuild(s, event.ID)
+}
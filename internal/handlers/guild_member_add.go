@@ -3,7 +3,6 @@ package handlers
 import (
 	stdctx "context"
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
@@ -36,25 +35,14 @@ func HandleGuildMemberAdd(session *discordgo.Session, event *discordgo.GuildMemb
 	}
 	logging.Info("Member joined: userID=%s, guildID=%s", userID, event.GuildID)
 
-	targetGuildID := os.Getenv("DISCORD_GUILD_ID")
-	if targetGuildID == "" {
-		logging.Warning("HandleGuildMemberAdd: DISCORD_GUILD_ID environment variable is not set")
-		return
-	}
-
-	if event.GuildID != targetGuildID {
-		logging.Info("HandleGuildMemberAdd: guild ID mismatch (expected=%s, got=%s), ignoring", targetGuildID, event.GuildID)
-		return
-	}
-
-	settings, err := guildConfigService.GetWelcomeSettings(stdctx.Background(), targetGuildID)
+	settings, err := guildConfigService.GetWelcomeSettings(stdctx.Background(), event.GuildID)
 	if err != nil {
 		logging.Warning("Failed to load welcome settings: " + err.Error())
 		return
 	}
 
 	if settings == nil {
-		logging.Info("HandleGuildMemberAdd: welcome settings not configured for guild %s", targetGuildID)
+		logging.Info("HandleGuildMemberAdd: welcome settings not configured for guild %s", event.GuildID)
 		return
 	}
 
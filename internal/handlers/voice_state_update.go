@@ -2,15 +2,19 @@ package handlers
 
 import (
 	appctx "github.com/ekkolyth/ekko-bot/internal/context"
+	"github.com/ekkolyth/ekko-bot/internal/music"
 
 	"github.com/bwmarrin/discordgo"
 )
 
-func HandleVoiceStateUpdate(_ *discordgo.Session, update *discordgo.VoiceStateUpdate) {
+func HandleVoiceStateUpdate(s *discordgo.Session, update *discordgo.VoiceStateUpdate) {
 	if update == nil || update.VoiceState == nil {
 		return
 	}
 
 	state := update.VoiceState
 	appctx.SetUserVoiceChannel(state.GuildID, state.UserID, state.ChannelID)
+
+	// pause or leave when the bot is left alone, resume when someone rejoins
+	music.CheckVoiceChannel(s, state.GuildID)
 }
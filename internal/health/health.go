@@ -0,0 +1,163 @@
+package health
+
+import (
+	stdctx "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+// how long a single dependency may take before it counts as down
+const checkTimeout = 2 * time.Second
+
+// discordgo reconnects after five missed acks, a gateway quiet for longer than
+// this has died without noticing
+const gatewayStaleAfter = 2 * time.Minute
+
+// Check probes one dependency
+type Check struct {
+	Name string
+	Run  func(ctx stdctx.Context) error
+}
+
+// Result is the outcome of one check
+type Result struct {
+	OK        bool    `json:"ok"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the outcome of every check
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// OK reports whether every check passed
+func (r Report) OK() bool {
+	return r.Status == "ok"
+}
+
+// Run executes the checks concurrently
+func Run(ctx stdctx.Context, checks ...Check) Report {
+	report := Report{Status: "ok", Checks: make(map[string]Result, len(checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+
+			checkCtx, cancel := stdctx.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+
+			started := time.Now()
+			err := check.Run(checkCtx)
+			result := Result{
+				OK:        err == nil,
+				LatencyMS: float64(time.Since(started).Microseconds()) / 1000,
+			}
+			if err != nil {
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			report.Checks[check.Name] = result
+			if err != nil {
+				report.Status = "fail"
+			}
+			mu.Unlock()
+		}(check)
+	}
+	wg.Wait()
+
+	return report
+}
+
+// Handler serves the report as JSON, 503 when a check fails
+func Handler(checks ...Check) http.HandlerFunc {
+	return func(write http.ResponseWriter, read *http.Request) {
+		report := Run(read.Context(), checks...)
+
+		status := http.StatusOK
+		if !report.OK() {
+			status = http.StatusServiceUnavailable
+		}
+
+		write.Header().Set("Content-Type", "application/json; charset=utf-8")
+		write.Header().Set("Cache-Control", "no-store")
+		write.WriteHeader(status)
+		_ = json.NewEncoder(write).Encode(report)
+	}
+}
+
+// Redis pings the server
+func Redis(client *redis.Client) Check {
+	return Check{Name: "redis", Run: func(ctx stdctx.Context) error {
+		if client == nil {
+			return errors.New("client not initialized")
+		}
+		return client.Ping(ctx).Err()
+	}}
+}
+
+// Postgres runs a trivial query
+func Postgres(pool *pgxpool.Pool) Check {
+	return Check{Name: "postgres", Run: func(ctx stdctx.Context) error {
+		if pool == nil {
+			return errors.New("pool not initialized")
+		}
+		var one int
+		return pool.QueryRow(ctx, "SELECT 1").Scan(&one)
+	}}
+}
+
+// DiscordGateway checks the session is connected and still receiving heartbeat acks
+func DiscordGateway(session *discordgo.Session) Check {
+	return Check{Name: "discord_gateway", Run: func(stdctx.Context) error {
+		if session == nil {
+			return errors.New("session not initialized")
+		}
+
+		session.RLock()
+		ready := session.DataReady
+		lastAck := session.LastHeartbeatAck
+		session.RUnlock()
+
+		if !ready {
+			return errors.New("gateway not ready")
+		}
+		if since := time.Since(lastAck); since > gatewayStaleAfter {
+			return fmt.Errorf("no heartbeat ack for %s", since.Round(time.Second))
+		}
+		return nil
+	}}
+}
+
+// Binary checks an executable is on PATH
+func Binary(name string) Check {
+	return Check{Name: name, Run: func(stdctx.Context) error {
+		_, err := exec.LookPath(name)
+		return err
+	}}
+}
+
+// NewMux serves /healthz for liveness and /readyz with the checks
+func NewMux(checks ...Check) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(write http.ResponseWriter, read *http.Request) {
+		write.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_ = json.NewEncoder(write).Encode(map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("GET /readyz", Handler(checks...))
+	return mux
+}
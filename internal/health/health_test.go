@@ -0,0 +1,52 @@
+package health
+
+import (
+	stdctx "context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandlerReportsFailingCheck(t *testing.T) {
+	handler := Handler(
+		Check{Name: "up", Run: func(stdctx.Context) error { return nil }},
+		Check{Name: "down", Run: func(stdctx.Context) error { return errors.New("connection refused") }},
+	)
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", recorder.Code)
+	}
+
+	var report Report
+	if err := json.NewDecoder(recorder.Body).Decode(&report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if report.Status != "fail" {
+		t.Errorf("expected status fail, got %q", report.Status)
+	}
+	if !report.Checks["up"].OK {
+		t.Errorf("expected up check to pass")
+	}
+	if down := report.Checks["down"]; down.OK || down.Error != "connection refused" {
+		t.Errorf("unexpected down result %+v", down)
+	}
+}
+
+func TestRunHonoursTimeout(t *testing.T) {
+	report := Run(stdctx.Background(), Check{Name: "slow", Run: func(ctx stdctx.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+
+	if report.OK() {
+		t.Fatalf("expected slow check to fail")
+	}
+	if latency := report.Checks["slow"].LatencyMS; latency < float64(checkTimeout.Milliseconds()) {
+		t.Errorf("expected latency of at least the timeout, got %.1fms", latency)
+	}
+}
@@ -0,0 +1,74 @@
+package logging
+
+import (
+	stdctx "context"
+	"log/slog"
+)
+
+type attrsKey struct{}
+
+// WithAttrs returns a copy of ctx whose log lines carry the given key/value
+// pairs, e.g. WithAttrs(ctx, "guild_id", guildID). Empty string values are
+// skipped so callers don't need to check them.
+func WithAttrs(ctx stdctx.Context, args ...any) stdctx.Context {
+	attrs := attrsFromContext(ctx)
+	added := make([]slog.Attr, 0, len(attrs)+len(args)/2)
+	added = append(added, attrs...)
+	added = append(added, nonEmptyAttrs(args)...)
+	return stdctx.WithValue(ctx, attrsKey{}, added)
+}
+
+// FromContext returns the shared logger with the attributes stored in ctx
+func FromContext(ctx stdctx.Context) *slog.Logger {
+	return withAttrs(attrsFromContext(ctx))
+}
+
+// With returns the shared logger with the given key/value pairs, skipping
+// empty string values like WithAttrs
+func With(args ...any) *slog.Logger {
+	return withAttrs(nonEmptyAttrs(args))
+}
+
+func withAttrs(attrs []slog.Attr) *slog.Logger {
+	if len(attrs) == 0 {
+		return Logger()
+	}
+	args := make([]any, len(attrs))
+	for i, attr := range attrs {
+		args[i] = attr
+	}
+	return Logger().With(args...)
+}
+
+func attrsFromContext(ctx stdctx.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+// pair up key/value arguments the way slog does, dropping empty strings
+func nonEmptyAttrs(args []any) []slog.Attr {
+	var attrs []slog.Attr
+	for len(args) > 0 {
+		switch key := args[0].(type) {
+		case slog.Attr:
+			attrs = append(attrs, key)
+			args = args[1:]
+		case string:
+			if len(args) == 1 {
+				attrs = append(attrs, slog.String("!BADKEY", key))
+				return attrs
+			}
+			if value, ok := args[1].(string); !ok || value != "" {
+				attrs = append(attrs, slog.Any(key, args[1]))
+			}
+			args = args[2:]
+		default:
+			attrs = append(attrs, slog.Any("!BADKEY", key))
+			args = args[1:]
+		}
+	}
+	return attrs
+}
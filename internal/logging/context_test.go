@@ -0,0 +1,33 @@
+package logging
+
+import (
+	"bytes"
+	stdctx "context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestFromContextCarriesAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	previous := logger.Load()
+	logger.Store(slog.New(slog.NewJSONHandler(&buf, nil)))
+	defer logger.Store(previous)
+
+	ctx := WithAttrs(stdctx.Background(), "request_id", "req-1", "user_id", "")
+	ctx = WithAttrs(ctx, "guild_id", "42")
+	FromContext(ctx).Info("hello", "error", "boom")
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("decode %q: %v", buf.String(), err)
+	}
+	for key, want := range map[string]string{"msg": "hello", "request_id": "req-1", "guild_id": "42", "error": "boom"} {
+		if line[key] != want {
+			t.Errorf("%s = %v, want %q", key, line[key], want)
+		}
+	}
+	if _, ok := line["user_id"]; ok {
+		t.Errorf("empty user_id should be skipped, got %v", line["user_id"])
+	}
+}
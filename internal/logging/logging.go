@@ -2,42 +2,87 @@ package logging
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
+	"os"
+	"sync/atomic"
 
 	"github.com/ekkolyth/ekko-bot/internal/config"
 )
 
-// Simple logging utility with color support for terminal output, used across the bot.
+// Leveled logging used across the bot and API, built on log/slog.
+
+var logger atomic.Pointer[slog.Logger]
+
+func init() {
+	Configure()
+}
+
+// Configure installs the handler described by LOG_LEVEL and LOG_FORMAT. Call
+// it again once .env files are loaded.
+func Configure() {
+	options := &slog.HandlerOptions{Level: config.LogLevel()}
+
+	var handler slog.Handler
+	if config.LogJSON() {
+		handler = slog.NewJSONHandler(os.Stderr, options)
+	} else {
+		handler = slog.NewTextHandler(os.Stderr, options)
+	}
+
+	l := slog.New(handler)
+	logger.Store(l)
+	slog.SetDefault(l) // routes the standard log package through the same handler
+}
+
+// Logger returns the shared logger
+func Logger() *slog.Logger {
+	return logger.Load()
+}
 
 func MessageCreate(username, message string) {
-	log.Println(config.CLI_GREEN + "[MSG]" + username + ":" + config.CLI_RESET + message)
+	Logger().Debug("message", "user", username, "content", message)
 }
 
 func InteractionCreate(username, command string, args string) {
-	log.Println(config.CLI_CYAN + "[INT]" + config.CLI_BRIGHT_CYAN + username + ": " + config.CLI_CYAN + command + config.CLI_RESET + args)
+	Logger().Info("interaction", "user", username, "command", command, "args", args)
 }
 
 func Error(message string) {
-	log.Println(config.CLI_RED + "[FATAL]" + config.CLI_RESET + message)
+	Logger().Error(message)
 }
 
-func Fatal(message string, error error) {
-	log.Fatal(config.CLI_RED + "[FATAL]" + config.CLI_RESET + message)
+// Fatal logs the message and error, then exits
+func Fatal(message string, err error) {
+	if err != nil {
+		Logger().Error(message, "error", err)
+	} else {
+		Logger().Error(message)
+	}
+	os.Exit(1)
 }
 
 func Info(message string, args ...any) {
-	output := fmt.Sprintf(message, args...)
-	log.Println(config.CLI_BLUE + "[INFO]:" + config.CLI_RESET + output)
+	if len(args) > 0 {
+		message = fmt.Sprintf(message, args...)
+	}
+	Logger().Info(message)
+}
+
+func Debug(message string, args ...any) {
+	if len(args) > 0 {
+		message = fmt.Sprintf(message, args...)
+	}
+	Logger().Debug(message)
 }
 
 func Warning(message string) {
-	log.Println(config.CLI_YELLOW + "[WARN]:" + config.CLI_RESET + message)
+	Logger().Warn(message)
 }
 
 func Dgvoice(message string) {
-	log.Println(config.CLI_RED + "[VOICE]: " + config.CLI_RESET + message)
+	Logger().Warn(message, "component", "voice")
 }
 
 func Api(message string) {
-	log.Println(config.CLI_BRIGHT_CYAN + "[API]:" + config.CLI_RESET + message)
+	Logger().Info(message, "component", "api")
 }
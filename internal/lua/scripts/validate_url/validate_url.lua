@@ -38,6 +38,16 @@ local function validate_url(input_url)
         return true, "spotify", nil
     end
 
+    -- send to soundcloud
+    if input_url:match("^https?://%w*%.?soundcloud%.com/[^/?#]+/[^/?#]+") then
+        return true, "soundcloud", nil
+    end
+
+    -- send to bandcamp
+    if input_url:match("^https?://[%w%-]+%.bandcamp%.com/") then
+        return true, "bandcamp", nil
+    end
+
     return false, nil, 3 -- error code 3: unsupported provider
 end
 
@@ -2,6 +2,7 @@
 -- ERROR CODES
 -- 4 = INVALID YOUTUBE URL FORMAT
 -- 5 = SHORTS URL NOT ACCEPTED
+-- 6 = NOT A PLAYLIST URL
 -- =============================
 
 
@@ -46,7 +47,43 @@ local function validate_youtube_url(input_url)
 end
 
 
+-- Convert a t= / start= value ("90", "90s", "1h2m3s") to seconds
+local function parse_start_seconds(value)
+    if not value or value == "" then
+        return nil
+    end
+
+    if value:match("^%d+s?$") then
+        return tonumber(value:match("^(%d+)"))
+    end
+
+    if not value:match("^[%dhms]+$") then
+        return nil
+    end
+
+    local hours = tonumber(value:match("(%d+)h") or "0")
+    local minutes = tonumber(value:match("(%d+)m") or "0")
+    local seconds = tonumber(value:match("(%d+)s") or "0")
+    return hours * 3600 + minutes * 60 + seconds
+end
+
+
+-- Find the start time in the query string or fragment, in seconds
+local function extract_start_seconds(input_url)
+    for key, value in input_url:gmatch("[?&#]([%w_]+)=([^&#]*)") do
+        if key == "t" or key == "start" then
+            local seconds = parse_start_seconds(value)
+            if seconds and seconds > 0 then
+                return seconds
+            end
+        end
+    end
+    return nil
+end
+
+
 -- Normalize any valid YouTube URL
+-- A t= or start= timestamp is kept as &t=<seconds>
 local function normalize_youtube_url(input_url)
     input_url = input_url:match("^%s*(.-)%s*$") -- trim
 
@@ -55,6 +92,8 @@ local function normalize_youtube_url(input_url)
         return nil, err
     end
 
+    local start_seconds = extract_start_seconds(input_url)
+
     -- =============================
     -- Normalize youtu.be shortlinks
     -- =============================
@@ -86,10 +125,39 @@ local function normalize_youtube_url(input_url)
         end
     end
 
+    -- Strip fragments (#t=90 is handled above)
+    local hash_pos = input_url:find("#", 1, true)
+    if hash_pos then
+        input_url = input_url:sub(1, hash_pos - 1)
+    end
+
+    if start_seconds then
+        input_url = input_url .. "&t=" .. start_seconds
+    end
+
     return input_url, nil
 end
 
 
+-- Normalize a YouTube playlist URL to https://www.youtube.com/playlist?list=ID
+-- watch?v=...&list=... links are treated as single videos
+local function normalize_youtube_playlist_url(input_url)
+    input_url = input_url:match("^%s*(.-)%s*$") -- trim
+
+    if not input_url:match("^https?://%w*%.?youtube%.com/playlist%?") then
+        return nil, 6
+    end
+
+    local list_id = input_url:match("[?&]list=([%w%-_]+)")
+    if not list_id then
+        return nil, 6
+    end
+
+    return "https://www.youtube.com/playlist?list=" .. list_id, nil
+end
+
+
 return {
-    normalize_youtube_url = normalize_youtube_url
+    normalize_youtube_url = normalize_youtube_url,
+    normalize_youtube_playlist_url = normalize_youtube_playlist_url
 }
@@ -27,6 +27,9 @@ var (
 
 type Script struct {
 	State *lua.LState
+
+	// an LState is not safe for concurrent use
+	mu sync.Mutex
 }
 
 // initialize the global lua script state
@@ -76,6 +79,8 @@ func (script *Script) LoadScript(scriptPath string) error {
 		moduleName := extractModuleName(scriptPath)
 		wrappedScript := fmt.Sprintf("local _module = (function()\n%s\nend)(); %s = _module", string(scriptContent), moduleName)
 
+		script.mu.Lock()
+		defer script.mu.Unlock()
 		if err := script.State.DoString(wrappedScript); err != nil {
 			loader.err = fmt.Errorf("failed to load %s: %w", scriptPath, err)
 			return
@@ -95,6 +100,9 @@ func extractModuleName(scriptPath string) string {
 
 // call a lua function and return its values
 func (script *Script) CallLuaFunc(moduleName, funcName string, numReturns int, args ...lua.LValue) ([]lua.LValue, error) {
+	script.mu.Lock()
+	defer script.mu.Unlock()
+
 	mod := script.State.GetGlobal(moduleName)
 	if mod == nil || mod.Type() != lua.LTTable {
 		return nil, fmt.Errorf("module %s not found", moduleName)
@@ -0,0 +1,155 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "ekko"
+
+var (
+	// CommandsTotal counts dispatched commands by name and where they came from
+	CommandsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "commands_total",
+		Help:      "Commands executed by name and source type.",
+	}, []string{"command", "source"})
+
+	// CommandErrorsTotal counts commands that were rejected or panicked
+	CommandErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "command_errors_total",
+		Help:      "Commands that failed by name and reason (disabled, unknown, forbidden, invalid, panic).",
+	}, []string{"command", "reason"})
+
+	// QueueLength is the number of upcoming tracks per queue, a guild has one
+	// per voice channel so sum by guild_id for the guild's total
+	QueueLength = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "queue_length",
+		Help:      "Tracks waiting in the queue by guild and voice channel.",
+	}, []string{"guild_id", "voice_channel_id"})
+
+	// ProcessSpawnsTotal counts yt-dlp and ffmpeg processes started
+	ProcessSpawnsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "process_spawns_total",
+		Help:      "External processes started by binary.",
+	}, []string{"binary"})
+
+	// ProcessFailuresTotal counts yt-dlp and ffmpeg processes that failed to start or exited with an error
+	ProcessFailuresTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "process_failures_total",
+		Help:      "External processes that failed to start or exited non-zero by binary.",
+	}, []string{"binary"})
+
+	// ProcessDuration is how long yt-dlp and ffmpeg processes ran
+	ProcessDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "process_duration_seconds",
+		Help:      "Run time of external processes by binary.",
+		Buckets:   []float64{0.5, 1, 5, 15, 60, 180, 300, 600, 1800, 3600, 10800},
+	}, []string{"binary"})
+
+	// MetadataFetchDuration is how long track metadata lookups take
+	MetadataFetchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "metadata_fetch_duration_seconds",
+		Help:      "Latency of track metadata lookups by kind and outcome.",
+		Buckets:   []float64{0.25, 0.5, 1, 2, 4, 8, 16, 32},
+	}, []string{"kind", "outcome"})
+
+	// PrefetchesTotal counts next tracks decoded ahead by whether they were played
+	PrefetchesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "prefetches_total",
+		Help:      "Next tracks decoded ahead by outcome (used, discarded, failed).",
+	}, []string{"outcome"})
+
+	// OpusFramesSent counts encoded frames handed to the voice connection
+	OpusFramesSent = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "opus_frames_sent_total",
+		Help:      "Opus frames sent to Discord voice connections.",
+	})
+
+	// SendUnderruns counts waits for PCM long enough to drain the voice send buffer
+	SendUnderruns = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "send_underruns_total",
+		Help:      "Times the voice sender ran out of PCM, including resuming after a pause.",
+	})
+
+	// HTTPRequestDuration is API latency by route pattern
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "API request latency by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+)
+
+// Handler serves the registered metrics
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// RegisterVoiceConnections exports the number of active voice connections,
+// read from count at scrape time
+func RegisterVoiceConnections(count func() int) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "voice_connections",
+		Help:      "Active Discord voice connections.",
+	}, func() float64 {
+		return float64(count())
+	})
+}
+
+// ObserveProcess records a finished external process
+func ObserveProcess(binary string, started time.Time, err error) {
+	ProcessDuration.WithLabelValues(binary).Observe(time.Since(started).Seconds())
+	if err != nil {
+		ProcessFailuresTotal.WithLabelValues(binary).Inc()
+	}
+}
+
+// ObserveMetadata records a finished metadata lookup
+func ObserveMetadata(kind string, started time.Time, err error) {
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	MetadataFetchDuration.WithLabelValues(kind, outcome).Observe(time.Since(started).Seconds())
+}
+
+// Middleware records request latency labelled by the matched chi route, so
+// path parameters don't explode the label set
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(write http.ResponseWriter, read *http.Request) {
+		started := time.Now()
+		wrapped := middleware.NewWrapResponseWriter(write, read.ProtoMajor)
+		next.ServeHTTP(wrapped, read)
+
+		route := "unmatched"
+		if routeCtx := chi.RouteContext(read.Context()); routeCtx != nil {
+			if pattern := routeCtx.RoutePattern(); pattern != "" {
+				route = pattern
+			}
+		}
+
+		status := wrapped.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		HTTPRequestDuration.WithLabelValues(read.Method, route, strconv.Itoa(status)).Observe(time.Since(started).Seconds())
+	})
+}
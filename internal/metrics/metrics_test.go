@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestMiddlewareLabelsByRoutePattern(t *testing.T) {
+	router := chi.NewRouter()
+	router.Use(Middleware)
+	router.Get("/api/guilds/{guildID}/queue", func(write http.ResponseWriter, read *http.Request) {
+		write.WriteHeader(http.StatusTeapot)
+	})
+
+	for _, path := range []string{"/api/guilds/1/queue", "/api/guilds/2/queue", "/nope"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	got := map[string]uint64{}
+	collected := make(chan prometheus.Metric, 8)
+	HTTPRequestDuration.Collect(collected)
+	close(collected)
+	for metric := range collected {
+		var sample dto.Metric
+		if err := metric.Write(&sample); err != nil {
+			t.Fatal(err)
+		}
+		key := ""
+		for _, label := range sample.GetLabel() {
+			key += label.GetValue() + " "
+		}
+		got[key] = sample.GetHistogram().GetSampleCount()
+	}
+
+	want := map[string]uint64{
+		"GET /api/guilds/{guildID}/queue 418 ": 2,
+		"GET unmatched 404 ":                   1,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("series = %v, want %v", got, want)
+	}
+	for key, count := range want {
+		if got[key] != count {
+			t.Errorf("%q = %d, want %d", key, got[key], count)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package music
+
+import (
+	stdcontext "context"
+	"errors"
+	"fmt"
+
+	"github.com/ekkolyth/ekko-bot/internal/config"
+	"github.com/ekkolyth/ekko-bot/internal/context"
+	"github.com/ekkolyth/ekko-bot/internal/youtube"
+)
+
+// addPlaylist expands a playlist into individual tracks and queues them with one summary reply
+func addPlaylist(ctx *context.Context, store context.QueueStore, guildID, playlistURL string, isAPICall bool) error {
+	if ctx.SourceType == context.SourceTypeInteraction {
+		// To avoid the discord timeout for interactions
+		ctx.Reply("Loading playlist...")
+	}
+
+	playlist, err := youtube.GetPlaylist(playlistURL, config.PlaylistLimit())
+	if err != nil {
+		return replyError(ctx, "Failed to load playlist.")
+	}
+
+	var tracks []*context.TrackInfo
+	for _, entry := range playlist.Entries {
+		track := &context.TrackInfo{
+			URL:       entry.URL,
+			Title:     entry.Title,
+			Artist:    entry.Artist,
+			Duration:  entry.Duration,
+			Thumbnail: entry.Thumbnail,
+			AddedBy:   ctx.RequesterTag,
+			AddedByID: ctx.RequesterDiscordUserID,
+		}
+		if track.Title == "" {
+			track.Title = track.URL
+		}
+		tracks = append(tracks, track)
+	}
+
+	name := playlist.Title
+	if name == "" {
+		name = playlist.URL
+	}
+	return queueTracks(ctx, store, guildID, tracks, isAPICall, func(added int) string {
+		summary := fmt.Sprintf("Added %d tracks from %s", added, name)
+		if playlist.Total > added {
+			summary += fmt.Sprintf(" (first %d of %d)", added, playlist.Total)
+		}
+		return summary
+	})
+}
+
+// queueTracks appends tracks in order with their metadata and one summary
+// reply, then starts playback when the queue was idle. describe builds the
+// summary from the number of tracks added.
+func queueTracks(ctx *context.Context, store context.QueueStore, guildID string, tracks []*context.TrackInfo, isAPICall bool, describe func(added int) string) error {
+	queueKey := context.QueueKey(guildID, ctx.VoiceChannelID)
+	initQueueVolume(store, guildID, queueKey)
+
+	var added []*context.TrackInfo
+	for _, track := range tracks {
+		if err := store.Append(queueKey, track); err != nil {
+			ctx.Logger().Error("Failed to enqueue track", "error", err)
+			break
+		}
+		if err := store.SaveMetadata(queueKey, track.URL, track); err != nil {
+			ctx.Logger().Error("Failed to cache metadata", "error", err)
+		}
+		added = append(added, track)
+	}
+
+	if len(added) == 0 {
+		return replyError(ctx, "Failed to add tracks to queue.")
+	}
+
+	// Persist recently played entries in background
+	go func(guild, voiceChannel string) {
+		for _, track := range added {
+			if recordErr := Record(stdcontext.Background(), RecordParams{
+				GuildID:         guild,
+				VoiceChannelID:  voiceChannel,
+				URL:             track.URL,
+				Title:           track.Title,
+				Artist:          track.Artist,
+				DurationSeconds: track.Duration,
+				Thumbnail:       track.Thumbnail,
+				AddedBy:         track.AddedBy,
+				AddedByID:       track.AddedByID,
+			}); recordErr != nil {
+				ctx.Logger().Error("Failed to record recently played", "error", recordErr)
+				return
+			}
+		}
+	}(guildID, ctx.VoiceChannelID)
+
+	summary := describe(len(added))
+
+	isAlreadyPlaying, err := store.IsPlaying(queueKey)
+	if err != nil {
+		ctx.Logger().Error("Failed to read queue state", "error", err)
+		return replyError(ctx, "Unable to read queue state.")
+	}
+
+	if !isAPICall {
+		ctx.Reply(summary)
+	} else {
+		ctx.Logger().Info("Added tracks to queue via API", "summary", summary)
+	}
+
+	if !isAlreadyPlaying {
+		_ = store.SetPlaying(queueKey, true)
+		ctx.Logger().Info("Starting queue processing for queue: " + queueKey)
+		ProcessQueue(ctx)
+	} else {
+		ctx.Logger().Info("Bot already playing in this channel, just added to queue: " + queueKey)
+	}
+	return nil
+}
+
+// replyError replies message and returns it as an error for callers that
+// answer elsewhere too, e.g. the API through the command bus
+func replyError(ctx *context.Context, message string) error {
+	ctx.Reply(message)
+	return errors.New(message)
+}
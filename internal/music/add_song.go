@@ -2,25 +2,28 @@ package music
 
 import (
 	stdcontext "context"
-	"os"
+	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/ekkolyth/ekko-bot/internal/api/httpx"
+	"github.com/ekkolyth/ekko-bot/internal/config"
 	"github.com/ekkolyth/ekko-bot/internal/context"
 	"github.com/ekkolyth/ekko-bot/internal/discord"
-	"github.com/ekkolyth/ekko-bot/internal/logging"
-	"github.com/ekkolyth/ekko-bot/internal/youtube"
+	"github.com/ekkolyth/ekko-bot/internal/provider"
+	"github.com/ekkolyth/ekko-bot/internal/spotify"
 )
 
-func AddSong(ctx *context.Context, search_mode bool, apiURL ...string) { // search_mode - false for play, true for search
+// AddSong queues a link, search result or attachment and starts playback. The
+// returned error is what was replied when nothing could be queued.
+func AddSong(ctx *context.Context, search_mode bool, apiURL ...string) error { // search_mode - false for play, true for search
 	var url string
 	var guildID string
 	var isAPICall bool
 
 	store := context.GetQueueStore()
 	if store == nil {
-		ctx.Reply("Queue store unavailable")
-		return
+		return replyError(ctx, "Queue store unavailable")
 	}
 
 	// Determine if this is an API call or Discord command
@@ -29,19 +32,10 @@ func AddSong(ctx *context.Context, search_mode bool, apiURL ...string) { // sear
 		isAPICall = true
 		url = strings.TrimSpace(apiURL[0])
 
-		// For API calls, get guildID from context or environment
-		if ctx != nil {
-			guildID = ctx.GetGuildID()
-		}
-
-		// If no guildID from context, get from DISCORD_GUILD_ID environment variable
+		// For API calls the guild comes from the routed request
+		guildID = ctx.GetGuildID()
 		if guildID == "" {
-			guildID = os.Getenv("DISCORD_GUILD_ID")
-			if guildID == "" {
-				ctx.Reply("Missing DISCORD_GUILD_ID environment variable")
-				return
-			}
-			logging.Info("Using DISCORD_GUILD_ID from environment for API call: " + guildID)
+			return replyError(ctx, "Missing guild ID")
 		}
 	} else {
 		// Discord command - get URL from context
@@ -50,8 +44,7 @@ func AddSong(ctx *context.Context, search_mode bool, apiURL ...string) { // sear
 
 		// Check voice channel only for Discord commands
 		if !discord.IsUserInVoiceChannel(ctx) {
-			ctx.Reply("You must be in a voice channel to use this command.")
-			return
+			return replyError(ctx, "You must be in a voice channel to use this command.")
 		}
 	}
 
@@ -70,18 +63,16 @@ func AddSong(ctx *context.Context, search_mode bool, apiURL ...string) { // sear
 			searchQuery, searchQuerySafeToUse = httpx.SanitiseSearchQuery(searchQuery)
 			hadToSanitise = true
 			if !searchQuerySafeToUse {
-				ctx.Reply("Invalid search query")
-				return
+				return replyError(ctx, "Invalid search query")
 			}
 		}
 
-		var found_result bool
-		url, found_result = youtube.SearchYoutube(searchQuery)
+		var searchErr error
+		url, searchErr = provider.Search(provider.DefaultSearch, searchQuery)
 
-		if !found_result {
-			logging.Error("No results found for: " + searchQuery)
-			ctx.Reply("No results found for: " + searchQuery)
-			return
+		if searchErr != nil {
+			ctx.Logger().Error("No results found for: "+searchQuery, "error", searchErr)
+			return replyError(ctx, "No results found for: "+searchQuery)
 		}
 
 		if hadToSanitise {
@@ -90,21 +81,56 @@ func AddSong(ctx *context.Context, search_mode bool, apiURL ...string) { // sear
 			ctx.Reply("Found: " + url)
 		}
 	} else {
+		// an attached file plays when no URL was given
+		if attachment := ctx.Attachment("file"); attachment != nil && ctx.Arguments["url"] == "" {
+			if int64(attachment.Size) > config.MaxFileSize() {
+				return replyError(ctx, fmt.Sprintf("That file is too large, the limit is %d MB.", config.MaxFileSize()>>20))
+			}
+			if !httpx.IsValidURL(attachment.URL) {
+				return replyError(ctx, "Attach an mp3, ogg, flac or wav file to play it.")
+			}
+			ctx.Arguments["url"] = attachment.URL
+		}
+
+		if ctx.Arguments["url"] == "" {
+			return replyError(ctx, "Usage: "+ctx.CommandPrefix()+"play <url>, or attach an audio file")
+		}
+
 		if len(ctx.Arguments["url"]) < 6 {
-			ctx.Reply("Invalid URL")
-			return
+			return replyError(ctx, "Invalid URL")
 		}
 
 		url = strings.TrimSpace(ctx.Arguments["url"])
 
 		if !httpx.IsValidURL(url) {
-			ctx.Reply("Invalid URL")
-			return
+			return replyError(ctx, "Invalid URL")
+		}
+
+		if _, _, isSpotify := spotify.ParseURL(url); isSpotify {
+			return addSpotify(ctx, store, guildID, url, isAPICall)
+		}
+
+		if playlistURL, isPlaylist := httpx.NormalizePlaylistURL(url); isPlaylist {
+			return addPlaylist(ctx, store, guildID, playlistURL, isAPICall)
 		}
 
+		if source, _ := provider.Lookup(url); source != nil {
+			if _, probes := source.(provider.Checker); probes && ctx.SourceType == context.SourceTypeInteraction {
+				// To avoid the discord timeout for interactions
+				ctx.Reply("Checking link...")
+			}
+		}
+		if err := provider.Check(url); err != nil {
+			ctx.Logger().Warn("Rejected file", "url", url, "error", err)
+			if errors.Is(err, provider.ErrTooLarge) {
+				return replyError(ctx, fmt.Sprintf("That file is too large, the limit is %d MB.", config.MaxFileSize()>>20))
+			}
+			return replyError(ctx, "That link isn't audio that can be played.")
+		}
 	}
 
 	queueKey := context.QueueKey(guildID, ctx.VoiceChannelID)
+	initQueueVolume(store, guildID, queueKey)
 
 	// Fetch video metadata and persist recently played entry in background
 	go func(requesterTag, requesterID, guild, voiceChannel string) {
@@ -118,17 +144,18 @@ func AddSong(ctx *context.Context, search_mode bool, apiURL ...string) { // sear
 			AddedByID: requesterID,
 		}
 
-		videoInfo, err := youtube.GetVideoInfo(url)
-		if err == nil && videoInfo != nil {
-			meta.Title = videoInfo.Title
-			meta.Artist = videoInfo.Artist
-			meta.Duration = videoInfo.Duration
-			meta.Thumbnail = videoInfo.Thumbnail
+		info, err := provider.Resolve(url)
+		if err == nil && info != nil {
+			meta.Title = info.Title
+			meta.Artist = info.Artist
+			meta.Duration = info.Duration
+			meta.Thumbnail = info.Thumbnail
+			meta.Live = info.Live
 
 			if saveErr := store.SaveMetadata(queueKey, url, meta); saveErr != nil {
-				logging.Error("Failed to cache metadata: " + saveErr.Error())
+				ctx.Logger().Error("Failed to cache metadata", "error", saveErr)
 			} else {
-				logging.Info("Cached metadata for: " + videoInfo.Title)
+				ctx.Logger().Info("Cached metadata for: " + info.Title)
 			}
 		}
 
@@ -143,7 +170,7 @@ func AddSong(ctx *context.Context, search_mode bool, apiURL ...string) { // sear
 			AddedBy:         meta.AddedBy,
 			AddedByID:       meta.AddedByID,
 		}); recordErr != nil {
-			logging.Error("Failed to record recently played: " + recordErr.Error())
+			ctx.Logger().Error("Failed to record recently played", "error", recordErr)
 		}
 	}(ctx.RequesterTag, ctx.RequesterDiscordUserID, guildID, ctx.VoiceChannelID)
 
@@ -158,29 +185,28 @@ func AddSong(ctx *context.Context, search_mode bool, apiURL ...string) { // sear
 	}
 
 	if err := store.Append(queueKey, queueTrack); err != nil {
-		logging.Error("Failed to enqueue track: " + err.Error())
-		ctx.Reply("Failed to add song to queue.")
-		return
+		ctx.Logger().Error("Failed to enqueue track", "error", err)
+		return replyError(ctx, "Failed to add song to queue.")
 	}
 
 	isAlreadyPlaying, err := store.IsPlaying(queueKey)
 	if err != nil {
-		logging.Error("Failed to read queue state: " + err.Error())
-		ctx.Reply("Unable to read queue state.")
-		return
+		ctx.Logger().Error("Failed to read queue state", "error", err)
+		return replyError(ctx, "Unable to read queue state.")
 	}
 
 	if !isAPICall {
 		ctx.Reply("Added to queue.")
 	} else {
-		logging.Info("Added to queue via API: %s", url)
+		ctx.Logger().Info("Added to queue via API", "url", url)
 	}
 
 	if !isAlreadyPlaying {
 		_ = store.SetPlaying(queueKey, true)
-		logging.Info("Starting queue processing for queue: " + queueKey)
+		ctx.Logger().Info("Starting queue processing for queue: " + queueKey)
 		ProcessQueue(ctx)
 	} else {
-		logging.Info("Bot already playing in this channel, just added to queue: " + queueKey)
+		ctx.Logger().Info("Bot already playing in this channel, just added to queue: " + queueKey)
 	}
+	return nil
 }
@@ -0,0 +1,92 @@
+package music
+
+import (
+	stdcontext "context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ekkolyth/ekko-bot/internal/config"
+	"github.com/ekkolyth/ekko-bot/internal/context"
+	"github.com/ekkolyth/ekko-bot/internal/provider"
+	"github.com/ekkolyth/ekko-bot/internal/spotify"
+)
+
+// each match runs a yt-dlp search, keep a few going at once for albums and playlists
+const spotifyMatchWorkers = 4
+
+// addSpotify reads a Spotify track, album or playlist, matches every track to
+// YouTube audio and queues the matches labelled with the Spotify metadata
+func addSpotify(ctx *context.Context, store context.QueueStore, guildID, spotifyURL string, isAPICall bool) error {
+	if ctx.SourceType == context.SourceTypeInteraction {
+		// To avoid the discord timeout for interactions
+		ctx.Reply("Loading from Spotify...")
+	}
+
+	collection, err := spotify.Default().Resolve(stdcontext.Background(), spotifyURL, config.PlaylistLimit())
+	if errors.Is(err, spotify.ErrNotConfigured) {
+		return replyError(ctx, "Spotify links are not enabled on this bot.")
+	}
+	if err != nil {
+		ctx.Logger().Error("Failed to read Spotify link", "url", spotifyURL, "error", err)
+		return replyError(ctx, "Failed to load Spotify link.")
+	}
+
+	// match concurrently but keep the Spotify order
+	matched := make([]*context.TrackInfo, len(collection.Tracks))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for range spotifyMatchWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				track := collection.Tracks[i]
+				match, err := provider.MatchYouTube(track.Title, track.Artist(), track.Duration)
+				if err != nil {
+					ctx.Logger().Warn("No YouTube match for Spotify track", "url", track.URL, "error", err)
+					continue
+				}
+				matched[i] = &context.TrackInfo{
+					URL:        match.URL,
+					Title:      track.Title,
+					Artist:     track.Artist(),
+					Duration:   track.Duration,
+					Thumbnail:  track.Thumbnail,
+					AddedBy:    ctx.RequesterTag,
+					AddedByID:  ctx.RequesterDiscordUserID,
+					SpotifyURL: track.URL,
+				}
+			}
+		}()
+	}
+	for i := range collection.Tracks {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	var tracks []*context.TrackInfo
+	for _, track := range matched {
+		if track != nil {
+			tracks = append(tracks, track)
+		}
+	}
+	if len(tracks) == 0 {
+		return replyError(ctx, "Couldn't find any of those tracks on YouTube.")
+	}
+
+	return queueTracks(ctx, store, guildID, tracks, isAPICall, func(added int) string {
+		if collection.Kind == "track" {
+			return "Added to queue: " + trackTitle(tracks[0])
+		}
+		summary := fmt.Sprintf("Added %d tracks from %s", added, collection.Title)
+		if missing := len(collection.Tracks) - len(tracks); missing > 0 {
+			summary += fmt.Sprintf(" (%d not found on YouTube)", missing)
+		}
+		if collection.Total > len(collection.Tracks) {
+			summary += fmt.Sprintf(" (first %d of %d)", len(collection.Tracks), collection.Total)
+		}
+		return summary
+	})
+}
@@ -0,0 +1,132 @@
+package music
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ekkolyth/ekko-bot/internal/config"
+	"github.com/ekkolyth/ekko-bot/internal/context"
+	"github.com/ekkolyth/ekko-bot/internal/discord"
+	"github.com/ekkolyth/ekko-bot/internal/logging"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// emptyChannel tracks a voice channel the bot was left alone in
+type emptyChannel struct {
+	timer  *time.Timer
+	paused bool // playback was paused by us, not by a user
+}
+
+var (
+	emptyChannels      = make(map[string]*emptyChannel)
+	emptyChannelsMutex sync.Mutex
+)
+
+// CheckVoiceChannel pauses playback when the last listener leaves the bot's
+// voice channel and leaves after a grace period. Playback resumes if someone
+// rejoins before then.
+func CheckVoiceChannel(s *discordgo.Session, guildID string) {
+	s.RLock()
+	vc := s.VoiceConnections[guildID]
+	s.RUnlock()
+	if vc == nil {
+		// not connected anymore, nothing left to leave
+		cancelEmptyChannels(guildID)
+		return
+	}
+
+	vc.RLock()
+	channelID := vc.ChannelID
+	vc.RUnlock()
+	if channelID == "" {
+		return
+	}
+
+	listeners, err := discord.ListenerCount(s, guildID, channelID)
+	if err != nil {
+		logging.Error("Failed to count voice channel listeners: " + err.Error())
+		return
+	}
+
+	queueKey := context.QueueKey(guildID, channelID)
+
+	emptyChannelsMutex.Lock()
+	defer emptyChannelsMutex.Unlock()
+
+	empty, waiting := emptyChannels[queueKey]
+
+	if listeners > 0 {
+		if !waiting {
+			return
+		}
+		empty.timer.Stop()
+		delete(emptyChannels, queueKey)
+
+		if empty.paused {
+			if player, ok := context.LookupPlayer(queueKey); ok && player.Resume() {
+				setPaused(queueKey, false)
+				logging.Info("Listener rejoined, resumed playback: " + queueKey)
+			}
+		}
+		return
+	}
+
+	if waiting {
+		return
+	}
+
+	empty = &emptyChannel{}
+	if player, ok := context.LookupPlayer(queueKey); ok && player.Pause() {
+		empty.paused = true
+		setPaused(queueKey, true)
+	}
+
+	logging.Info("Voice channel empty, leaving after grace period: " + queueKey)
+	empty.timer = time.AfterFunc(config.EmptyChannelTimeout(), func() {
+		emptyChannelsMutex.Lock()
+		current := emptyChannels[queueKey]
+		if current == empty {
+			delete(emptyChannels, queueKey)
+		}
+		emptyChannelsMutex.Unlock()
+
+		// someone rejoined while the timer fired
+		if current != empty {
+			return
+		}
+
+		store := context.GetQueueStore()
+		if store == nil {
+			logging.Error("Queue store unavailable for auto-leave")
+			return
+		}
+
+		logging.Info("Voice channel still empty, stopping playback: " + queueKey)
+		stopAndDisconnect(store, queueKey, vc)
+	})
+	emptyChannels[queueKey] = empty
+}
+
+// stop pending auto-leave timers for a guild
+func cancelEmptyChannels(guildID string) {
+	emptyChannelsMutex.Lock()
+	defer emptyChannelsMutex.Unlock()
+
+	for queueKey, empty := range emptyChannels {
+		if strings.HasPrefix(queueKey, guildID+":") {
+			empty.timer.Stop()
+			delete(emptyChannels, queueKey)
+		}
+	}
+}
+
+// mirror the player's pause state in the queue store
+func setPaused(queueKey string, value bool) {
+	if store := context.GetQueueStore(); store != nil {
+		if err := store.SetPaused(queueKey, value); err != nil {
+			logging.Error("Failed to update pause state: " + err.Error())
+		}
+	}
+}
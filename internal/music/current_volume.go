@@ -20,21 +20,6 @@ func CurrentVolume(ctx *context.Context) {
 		return
 	}
 
-	context.VolumeMutex.Lock()
-	currentVolume, ok := context.Volume[queueKey]
-	context.VolumeMutex.Unlock()
-
-	if !ok {
-		value, err := store.GetVolume(queueKey)
-		if err != nil {
-			value = 1.0
-		}
-		context.VolumeMutex.Lock()
-		context.Volume[queueKey] = value
-		context.VolumeMutex.Unlock()
-		currentVolume = value
-	}
-
-	currentVolume = currentVolume * 100.0
+	currentVolume := context.GetPlayer(queueKey).Volume() * 100.0
 	ctx.Reply(fmt.Sprintf("Current volume is %.1f%%", currentVolume))
 }
@@ -0,0 +1,50 @@
+package music
+
+import (
+	stdcontext "context"
+	"time"
+
+	"github.com/ekkolyth/ekko-bot/internal/context"
+	"github.com/ekkolyth/ekko-bot/internal/db"
+	"github.com/ekkolyth/ekko-bot/internal/logging"
+)
+
+var guildConfigService *db.GuildConfigService
+
+// SetGuildConfigService wires guild config lookups for playback defaults.
+func SetGuildConfigService(s *db.GuildConfigService) {
+	guildConfigService = s
+}
+
+// GuildPlaybackSettings returns the guild's playback defaults, nil when unavailable.
+func GuildPlaybackSettings(guildID string) *db.PlaybackSettings {
+	if guildConfigService == nil {
+		return nil
+	}
+
+	ctx, cancel := stdcontext.WithTimeout(stdcontext.Background(), 2*time.Second)
+	defer cancel()
+
+	settings, err := guildConfigService.GetPlaybackSettings(ctx, guildID)
+	if err != nil {
+		logging.Error("Failed to load guild playback settings: " + err.Error())
+		return nil
+	}
+	return settings
+}
+
+// initQueueVolume starts a new queue at the guild's default volume
+func initQueueVolume(store context.QueueStore, guildID, queueKey string) {
+	if _, ok := context.LookupPlayer(queueKey); ok {
+		return
+	}
+
+	settings := GuildPlaybackSettings(guildID)
+	if settings == nil {
+		return
+	}
+
+	if _, err := store.InitVolume(queueKey, float64(settings.Volume)/100.0); err != nil {
+		logging.Error("Failed to set default volume: " + err.Error())
+	}
+}
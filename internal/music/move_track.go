@@ -0,0 +1,65 @@
+package music
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/ekkolyth/ekko-bot/internal/context"
+	"github.com/ekkolyth/ekko-bot/internal/discord"
+)
+
+// MoveTrack moves a queued song to a new position (1-based, as shown by /queue)
+func MoveTrack(ctx *context.Context) {
+	from, fromErr := strconv.Atoi(ctx.Arguments["from"])
+	to, toErr := strconv.Atoi(ctx.Arguments["to"])
+	if fromErr != nil || toErr != nil {
+		ctx.Reply("Usage: move <from> <to>")
+		return
+	}
+
+	moveTrack(ctx, from, to)
+}
+
+// PlayNext moves a queued song to the front of the queue
+func PlayNext(ctx *context.Context) {
+	position, err := strconv.Atoi(ctx.Arguments["position"])
+	if err != nil {
+		ctx.Reply("Usage: playnext <position>")
+		return
+	}
+
+	moveTrack(ctx, position, 1)
+}
+
+func moveTrack(ctx *context.Context, from, to int) {
+	if !discord.EnsureVoiceChannelID(ctx) {
+		ctx.Reply("Could not determine your voice channel.")
+		return
+	}
+
+	queueKey := context.QueueKey(ctx.GetGuildID(), ctx.VoiceChannelID)
+	store := context.GetQueueStore()
+	if store == nil {
+		ctx.Reply("Queue store unavailable.")
+		return
+	}
+
+	length, err := store.Length(queueKey)
+	if err != nil {
+		ctx.Reply("Failed to load queue.")
+		return
+	}
+
+	if from < 1 || from > int(length) || to < 1 || to > int(length) {
+		ctx.Reply(fmt.Sprintf("Invalid position. The queue has %d songs.", length))
+		return
+	}
+
+	if err := store.Move(queueKey, from-1, to-1); err != nil {
+		ctx.Logger().Error("Failed to move track", "error", err)
+		ctx.Reply("Failed to move the song.")
+		return
+	}
+
+	ctx.Reply(fmt.Sprintf("Moved song %d to position %d.", from, to))
+}
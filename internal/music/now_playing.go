@@ -0,0 +1,75 @@
+package music
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ekkolyth/ekko-bot/internal/context"
+	"github.com/ekkolyth/ekko-bot/internal/discord"
+)
+
+const progressBarWidth = 20
+
+// NowPlaying shows the current song with a text progress bar
+func NowPlaying(ctx *context.Context) {
+	if !discord.EnsureVoiceChannelID(ctx) {
+		ctx.Reply("Could not determine your voice channel.")
+		return
+	}
+
+	queueKey := context.QueueKey(ctx.GetGuildID(), ctx.VoiceChannelID)
+
+	player, ok := context.LookupPlayer(queueKey)
+	if !ok {
+		ctx.Reply("Nothing is playing.")
+		return
+	}
+
+	state := player.State()
+	if state.NowPlaying == nil {
+		ctx.Reply("Nothing is playing.")
+		return
+	}
+
+	title := state.NowPlaying.Title
+	if title == "" {
+		title = state.NowPlaying.URL
+	}
+	if state.NowPlaying.Artist != "" {
+		title += " - " + state.NowPlaying.Artist
+	}
+
+	status := ""
+	if state.Paused {
+		status = " (paused)"
+	}
+
+	if state.NowPlaying.Live {
+		ctx.Reply(fmt.Sprintf("Now playing: %s%s\n🔴 LIVE, listening for %s", title, status, formatTimestamp(state.Position)))
+		return
+	}
+
+	duration := time.Duration(state.NowPlaying.Duration) * time.Second
+	timing := formatTimestamp(state.Position)
+	if duration > 0 {
+		timing += " / " + formatTimestamp(duration)
+	}
+
+	ctx.Reply(fmt.Sprintf("Now playing: %s%s\n`%s` %s", title, status, progressBar(state.Position, duration), timing))
+}
+
+// render position as a fixed width bar, empty when the duration is unknown
+func progressBar(position, duration time.Duration) string {
+	filled := 0
+	if duration > 0 {
+		filled = int(int64(progressBarWidth) * int64(position) / int64(duration))
+	}
+	if filled > progressBarWidth-1 {
+		filled = progressBarWidth - 1
+	}
+	if filled < 0 {
+		filled = 0
+	}
+	return strings.Repeat("▬", filled) + "🔘" + strings.Repeat("▬", progressBarWidth-1-filled)
+}
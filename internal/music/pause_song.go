@@ -23,26 +23,13 @@ func PauseSong(ctx *context.Context) {
 		return
 	}
 
-	context.PauseMutex.Lock()
-	currentState := context.Paused[queueKey]
-	newState := !currentState
-	context.Paused[queueKey] = newState
-	context.PauseMutex.Unlock()
+	newState := context.GetPlayer(queueKey).TogglePause()
 
 	_ = store.SetPaused(queueKey, newState)
 
-	context.PauseChMutex.Lock()
-	if ch, exists := context.PauseChs[queueKey]; exists {
-		select {
-		case ch <- newState:
-		default:
-		}
-	}
-	context.PauseChMutex.Unlock()
-
-	if currentState {
-		ctx.Reply("Resumed playback.")
-	} else {
+	if newState {
 		ctx.Reply("Paused playback.")
+	} else {
+		ctx.Reply("Resumed playback.")
 	}
 }
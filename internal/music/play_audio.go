@@ -1,47 +1,77 @@
 package music
 
 import (
+	"time"
+
 	"github.com/ekkolyth/ekko-bot/internal/context"
 	"github.com/ekkolyth/ekko-bot/internal/discord"
 	"github.com/ekkolyth/ekko-bot/internal/ffmpeg"
-	"github.com/ekkolyth/ekko-bot/internal/logging"
+	"github.com/ekkolyth/ekko-bot/internal/provider"
 
 	"github.com/bwmarrin/discordgo"
 )
 
-func PlayAudio(ctx *context.Context, url string, stop chan bool, pauseCh chan bool, done chan bool) {
-	defer close(done) // Signal when this function exits
-
+// PlayAudio joins the voice channel if needed and blocks until the track
+// finishes or interrupt is closed. Playback begins at the start offset, or
+// from decoder when the track was prefetched. Returns an error if nothing
+// could be played.
+func PlayAudio(ctx *context.Context, player *context.Player, track *context.TrackInfo, start time.Duration, decoder *ffmpeg.Decoder, interrupt <-chan struct{}) error {
 	var vc *discordgo.VoiceConnection
 	var err error
 
 	if !discord.BotInChannel(ctx) {
-		logging.Info("Bot not in channel, attempting to join voice channel: " + ctx.VoiceChannelID)
+		ctx.Logger().Info("Bot not in channel, attempting to join voice channel: " + ctx.VoiceChannelID)
 		vc, err = discord.JoinUserVoiceChannel(ctx)
 		if err != nil {
-			logging.Error("Error joining voice channel: " + err.Error())
+			ctx.Logger().Error("Error joining voice channel", "error", err)
 			ctx.Reply("Error joining voice channel.")
-			return
+			closeDecoder(decoder)
+			return err
 		}
-		logging.Info("Successfully joined voice channel")
+		ctx.Logger().Info("Successfully joined voice channel")
+
+		// Make sure voice connection is ready before starting
+		time.Sleep(100 * time.Millisecond)
 	} else {
-		logging.Info("Bot already in channel, getting existing connection")
+		ctx.Logger().Info("Bot already in channel, getting existing connection")
 		vc, err = discord.GetVoiceConnection(ctx)
 		if err != nil {
-			logging.Error("Error getting voice connection: " + err.Error())
+			ctx.Logger().Error("Error getting voice connection", "error", err)
 			ctx.Reply("Error with voice connection.")
-			return
+			closeDecoder(decoder)
+			return err
+		}
+	}
+
+	if decoder == nil {
+		decoder, err = openDecoder(track, start)
+		if err != nil {
+			ctx.Logger().Error("Error opening audio stream", "error", err)
+			return err
 		}
 	}
 
-	queueKey := context.QueueKey(ctx.GetGuildID(), ctx.VoiceChannelID)
+	if err := ffmpeg.StreamAudio(vc, decoder, player, interrupt); err != nil {
+		ctx.Logger().Error("Song playback failed", "error", err)
+		return err
+	}
+	ctx.Logger().Info("Song playback complete")
+	return nil
+}
 
-	songDone := make(chan bool)
-	go func() {
-		ffmpeg.StreamAudio(vc, url, queueKey, stop, pauseCh)
-		close(songDone)
-	}()
+// start decoding the track's audio from its provider
+var openDecoder = func(track *context.TrackInfo, start time.Duration) (*ffmpeg.Decoder, error) {
+	stream, err := provider.OpenStream(track.URL, track.Live)
+	if err != nil {
+		return nil, err
+	}
+	return ffmpeg.Decode(stream, start)
+}
 
-	<-songDone
-	logging.Info("Song playback complete")
+// close a decoder that won't be played, if any. Both are vars so prefetch
+// tests can stand in for ffmpeg.
+var closeDecoder = func(decoder *ffmpeg.Decoder) {
+	if decoder != nil {
+		decoder.Close()
+	}
 }
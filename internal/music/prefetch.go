@@ -0,0 +1,214 @@
+package music
+
+import (
+	stdcontext "context"
+	"sync"
+	"time"
+
+	"github.com/ekkolyth/ekko-bot/internal/config"
+	"github.com/ekkolyth/ekko-bot/internal/context"
+	"github.com/ekkolyth/ekko-bot/internal/ffmpeg"
+	"github.com/ekkolyth/ekko-bot/internal/logging"
+	"github.com/ekkolyth/ekko-bot/internal/metrics"
+	"github.com/ekkolyth/ekko-bot/internal/youtube"
+)
+
+// how long before the current track ends the next one is opened. Opening
+// earlier would leave yt-dlp's connection idle for the whole track.
+const prefetchLead = 30 * time.Second
+
+// prefetcher opens the queue's next track while the current one plays and
+// drops it again when the head of the queue changes
+type prefetcher struct {
+	store    context.QueueStore
+	queueKey string
+	player   *context.Player
+
+	mu      sync.Mutex
+	track   *context.TrackInfo // queue head the decoder was opened for
+	decoder *ffmpeg.Decoder
+	opening bool
+	// bumped by Take and discard so an open that finishes late is dropped
+	generation int
+	// head that failed to open, not retried until the head changes
+	failedURL string
+
+	cancel stdcontext.CancelFunc
+}
+
+// start watching the queue, nil when prefetching is disabled
+func startPrefetcher(store context.QueueStore, queueKey string, player *context.Player) *prefetcher {
+	if config.PrefetchBuffer() <= 0 {
+		return nil
+	}
+
+	ctx, cancel := stdcontext.WithCancel(stdcontext.Background())
+	p := &prefetcher{
+		store:    store,
+		queueKey: queueKey,
+		player:   player,
+		cancel:   cancel,
+	}
+	go p.run(ctx)
+	return p
+}
+
+// Take hands over the prefetched track and its decoder, nil when there is
+// none. Watching continues for the track after.
+func (p *prefetcher) Take() (*context.TrackInfo, *ffmpeg.Decoder) {
+	if p == nil {
+		return nil, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	track, decoder := p.track, p.decoder
+	p.track, p.decoder = nil, nil
+	p.generation++
+	return track, decoder
+}
+
+// Stop stops watching and drops any prefetched track
+func (p *prefetcher) Stop() {
+	if p == nil {
+		return
+	}
+	p.cancel()
+	p.discard()
+}
+
+func (p *prefetcher) run(ctx stdcontext.Context) {
+	events, err := p.store.Subscribe(ctx, p.queueKey)
+	if err != nil {
+		// the URL check when the track is taken still catches a changed queue
+		logging.Warning("Prefetch can't follow queue changes: " + err.Error())
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			switch event.Type {
+			case context.EventTrackAdded, context.EventTrackRemoved, context.EventQueueReordered, context.EventQueueCleared:
+				p.checkHead()
+			}
+		case <-ticker.C:
+			p.maybeOpen()
+		}
+	}
+}
+
+// drop the prefetched track when a skip, remove, move or shuffle changed the head
+func (p *prefetcher) checkHead() {
+	p.mu.Lock()
+	track := p.track
+	p.mu.Unlock()
+	if track == nil {
+		return
+	}
+
+	head, err := p.store.Peek(p.queueKey)
+	if err != nil || head == nil || head.URL != track.URL {
+		p.discard()
+	}
+}
+
+// open the head of the queue once the current track is close to its end
+func (p *prefetcher) maybeOpen() {
+	p.mu.Lock()
+	busy := p.decoder != nil || p.opening
+	p.mu.Unlock()
+	if busy {
+		return
+	}
+
+	state := p.player.State()
+	current := state.NowPlaying
+	if current == nil || current.Live || current.Duration <= 0 {
+		// between tracks, a live stream that only ends when skipped, or a
+		// track whose end isn't known
+		return
+	}
+	if time.Duration(current.Duration)*time.Second-state.Position > prefetchLead {
+		return
+	}
+	if mode, err := p.store.GetLoopMode(p.queueKey); err != nil || mode == context.LoopTrack {
+		return
+	}
+
+	head, err := p.store.Peek(p.queueKey)
+	if err != nil || head == nil {
+		return
+	}
+
+	p.mu.Lock()
+	if head.URL == p.failedURL {
+		p.mu.Unlock()
+		return
+	}
+	p.opening = true
+	generation := p.generation
+	p.mu.Unlock()
+
+	track := head
+	if meta, metaErr := p.store.LookupMetadata(p.queueKey, head.URL); metaErr == nil && meta != nil {
+		track = meta
+	} else {
+		// cache it so the queue loop doesn't resolve it again at the switch
+		track = resolveTrack(head)
+		if saveErr := p.store.SaveMetadata(p.queueKey, track.URL, track); saveErr != nil {
+			logging.Error("Failed to cache metadata: " + saveErr.Error())
+		}
+	}
+
+	decoder, err := openDecoder(track, youtube.StartOffset(track.URL))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.opening = false
+	if err != nil {
+		metrics.PrefetchesTotal.WithLabelValues("failed").Inc()
+		logging.Warning("Failed to prefetch next track: " + err.Error())
+		p.failedURL = head.URL
+		return
+	}
+	if generation != p.generation {
+		// taken or discarded while opening, the queue has moved on
+		go closeDecoder(decoder)
+		metrics.PrefetchesTotal.WithLabelValues("discarded").Inc()
+		return
+	}
+	p.track, p.decoder = track, decoder
+	p.failedURL = ""
+}
+
+func (p *prefetcher) discard() {
+	_, decoder := p.Take()
+	if decoder != nil {
+		closeDecoder(decoder)
+		metrics.PrefetchesTotal.WithLabelValues("discarded").Inc()
+	}
+}
+
+// return the prefetched decoder when it was opened for track, closing it otherwise
+func usePrefetched(prefetchedTrack *context.TrackInfo, decoder *ffmpeg.Decoder, track *context.TrackInfo) *ffmpeg.Decoder {
+	if decoder == nil {
+		return nil
+	}
+	if track == nil || prefetchedTrack.URL != track.URL {
+		closeDecoder(decoder)
+		metrics.PrefetchesTotal.WithLabelValues("discarded").Inc()
+		return nil
+	}
+	metrics.PrefetchesTotal.WithLabelValues("used").Inc()
+	return decoder
+}
@@ -0,0 +1,203 @@
+package music
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ekkolyth/ekko-bot/internal/context"
+	"github.com/ekkolyth/ekko-bot/internal/ffmpeg"
+)
+
+// prefetchStore answers the queue head and metadata from memory
+type prefetchStore struct {
+	context.QueueStore
+
+	mu   sync.Mutex
+	head *context.TrackInfo
+}
+
+func (s *prefetchStore) setHead(track *context.TrackInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.head = track
+}
+
+func (s *prefetchStore) Peek(string) (*context.TrackInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.head, nil
+}
+
+func (s *prefetchStore) GetLoopMode(string) (context.LoopMode, error) {
+	return context.LoopOff, nil
+}
+
+func (s *prefetchStore) LookupMetadata(_, url string) (*context.TrackInfo, error) {
+	return &context.TrackInfo{URL: url, Title: "Next", Duration: 180}, nil
+}
+
+// fakeDecoders stands in for ffmpeg, recording which decoders were opened and closed
+type fakeDecoders struct {
+	mu     sync.Mutex
+	opened []string
+	closed map[*ffmpeg.Decoder]bool
+}
+
+func useFakeDecoders(t *testing.T) *fakeDecoders {
+	t.Helper()
+	fake := &fakeDecoders{closed: make(map[*ffmpeg.Decoder]bool)}
+
+	previousOpen, previousClose := openDecoder, closeDecoder
+	openDecoder = func(track *context.TrackInfo, _ time.Duration) (*ffmpeg.Decoder, error) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		fake.opened = append(fake.opened, track.URL)
+		return &ffmpeg.Decoder{}, nil
+	}
+	closeDecoder = func(decoder *ffmpeg.Decoder) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		fake.closed[decoder] = true
+	}
+	t.Cleanup(func() { openDecoder, closeDecoder = previousOpen, previousClose })
+	return fake
+}
+
+func (f *fakeDecoders) isClosed(decoder *ffmpeg.Decoder) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.closed[decoder]
+}
+
+func (f *fakeDecoders) openCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.opened)
+}
+
+// newTestPrefetcher returns a prefetcher near the end of a 3 minute track
+// with next at the head of the queue
+func newTestPrefetcher(current, next *context.TrackInfo) (*prefetcher, *prefetchStore) {
+	store := &prefetchStore{head: next}
+	player := context.NewPlayer("guild:prefetch", 1.0)
+	player.StartTrack(current)
+	player.SetPosition(170 * time.Second)
+
+	return &prefetcher{
+		store:    store,
+		queueKey: "guild:prefetch",
+		player:   player,
+		cancel:   func() {},
+	}, store
+}
+
+func TestPrefetchOpensNextTrackNearTheEnd(t *testing.T) {
+	fake := useFakeDecoders(t)
+	next := &context.TrackInfo{URL: "https://youtu.be/next"}
+	p, _ := newTestPrefetcher(&context.TrackInfo{URL: "https://youtu.be/current", Duration: 180}, next)
+
+	p.maybeOpen()
+
+	track, decoder := p.Take()
+	if track == nil || track.URL != next.URL || decoder == nil {
+		t.Fatalf("Take() = %+v, %v; want the next track with a decoder", track, decoder)
+	}
+	if track, decoder := p.Take(); track != nil || decoder != nil {
+		t.Errorf("second Take() = %+v, %v; want nothing", track, decoder)
+	}
+	if fake.openCount() != 1 {
+		t.Errorf("opened %d decoders; want 1", fake.openCount())
+	}
+}
+
+func TestPrefetchSkipsUnknownDuration(t *testing.T) {
+	fake := useFakeDecoders(t)
+	p, _ := newTestPrefetcher(
+		&context.TrackInfo{URL: "https://example.com/stream"}, // Duration 0
+		&context.TrackInfo{URL: "https://youtu.be/next"},
+	)
+
+	p.maybeOpen()
+
+	if fake.openCount() != 0 {
+		t.Errorf("opened %d decoders for a track of unknown length; want 0", fake.openCount())
+	}
+	if track, decoder := p.Take(); track != nil || decoder != nil {
+		t.Errorf("Take() = %+v, %v; want nothing", track, decoder)
+	}
+}
+
+func TestPrefetchDroppedWhenHeadChanges(t *testing.T) {
+	fake := useFakeDecoders(t)
+	p, store := newTestPrefetcher(
+		&context.TrackInfo{URL: "https://youtu.be/current", Duration: 180},
+		&context.TrackInfo{URL: "https://youtu.be/next"},
+	)
+	p.maybeOpen()
+
+	p.mu.Lock()
+	decoder := p.decoder
+	p.mu.Unlock()
+	if decoder == nil {
+		t.Fatal("nothing prefetched")
+	}
+
+	// e.g. /playnext moved another track to the front
+	store.setHead(&context.TrackInfo{URL: "https://youtu.be/other"})
+	p.checkHead()
+
+	if !fake.isClosed(decoder) {
+		t.Error("decoder for the old head wasn't closed")
+	}
+	if track, decoder := p.Take(); track != nil || decoder != nil {
+		t.Errorf("Take() after the head changed = %+v, %v; want nothing", track, decoder)
+	}
+}
+
+func TestUsePrefetchedClosesDecoderForAnotherTrack(t *testing.T) {
+	fake := useFakeDecoders(t)
+	prefetched := &context.TrackInfo{URL: "https://youtu.be/next"}
+
+	// taken before the queue event arrived, then a different track was popped
+	decoder := &ffmpeg.Decoder{}
+	if got := usePrefetched(prefetched, decoder, &context.TrackInfo{URL: "https://youtu.be/other"}); got != nil {
+		t.Error("usePrefetched() returned the decoder of another track")
+	}
+	if !fake.isClosed(decoder) {
+		t.Error("decoder of another track wasn't closed")
+	}
+
+	decoder = &ffmpeg.Decoder{}
+	if got := usePrefetched(prefetched, decoder, &context.TrackInfo{URL: prefetched.URL}); got != decoder {
+		t.Error("usePrefetched() didn't return the decoder of the popped track")
+	}
+	if fake.isClosed(decoder) {
+		t.Error("decoder of the popped track was closed")
+	}
+}
+
+func TestPrefetchStopClosesDecoder(t *testing.T) {
+	fake := useFakeDecoders(t)
+	p, _ := newTestPrefetcher(
+		&context.TrackInfo{URL: "https://youtu.be/current", Duration: 180},
+		&context.TrackInfo{URL: "https://youtu.be/next"},
+	)
+	p.maybeOpen()
+
+	p.mu.Lock()
+	decoder := p.decoder
+	p.mu.Unlock()
+	if decoder == nil {
+		t.Fatal("nothing prefetched")
+	}
+
+	p.Stop()
+
+	if !fake.isClosed(decoder) {
+		t.Error("Stop() didn't close the prefetched decoder")
+	}
+	if track, decoder := p.Take(); track != nil || decoder != nil {
+		t.Errorf("Take() after Stop() = %+v, %v; want nothing", track, decoder)
+	}
+}
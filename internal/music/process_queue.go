@@ -4,47 +4,90 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/ekkolyth/ekko-bot/internal/config"
 	"github.com/ekkolyth/ekko-bot/internal/context"
 	"github.com/ekkolyth/ekko-bot/internal/discord"
+	"github.com/ekkolyth/ekko-bot/internal/ffmpeg"
 	"github.com/ekkolyth/ekko-bot/internal/logging"
+	"github.com/ekkolyth/ekko-bot/internal/metrics"
+	"github.com/ekkolyth/ekko-bot/internal/provider"
+	"github.com/ekkolyth/ekko-bot/internal/youtube"
 )
 
 func ProcessQueue(ctx *context.Context) {
 	store := context.GetQueueStore()
 	if store == nil {
-		logging.Error("Queue store unavailable for processing")
+		ctx.Logger().Error("Queue store unavailable for processing")
 		return
 	}
 
-	go func() {
-		queueKey := context.QueueKey(ctx.GetGuildID(), ctx.VoiceChannelID)
+	queueKey := context.QueueKey(ctx.GetGuildID(), ctx.VoiceChannelID)
+	player := context.GetPlayer(queueKey)
+
+	started := player.Play(func(player *context.Player) {
+		var replay *context.TrackInfo
+		var seekTo time.Duration
+		var seeking bool
+
+		// continue the track a restart interrupted from where it stopped
+		if resumed, position, err := store.TakeResume(queueKey); err != nil {
+			ctx.Logger().Error("Failed to read interrupted track", "error", err)
+		} else if resumed != nil {
+			replay, seekTo, seeking = resumed, position, true
+			ctx.Logger().Info("Resuming interrupted track", "url", resumed.URL, "position_ms", position.Milliseconds())
+			ctx.Reply(fmt.Sprintf("Resuming: %s", trackTitle(resumed)))
+		}
 
-		for {
-			nextTrack, err := store.PopNext(queueKey)
-			if err != nil {
-				logging.Error("Failed to pop next track: " + err.Error())
-				break
+		// opens the next track while the current one plays so they join up
+		prefetch := startPrefetcher(store, queueKey, player)
+		defer prefetch.Stop()
+		// channels come and go, don't keep a series for each one left
+		defer metrics.QueueLength.DeleteLabelValues(ctx.GetGuildID(), ctx.VoiceChannelID)
+
+		for !player.Stopping() {
+			nextTrack := replay
+			replay = nil
+
+			var decoder *ffmpeg.Decoder
+			if nextTrack == nil {
+				// claim the prefetch first, popping changes the head it watches
+				prefetchedTrack, prefetched := prefetch.Take()
+				popped, err := store.PopNext(queueKey)
+				if err != nil {
+					closeDecoder(prefetched)
+					ctx.Logger().Error("Failed to pop next track", "error", err)
+					break
+				}
+				nextTrack = popped
+				decoder = usePrefetched(prefetchedTrack, prefetched, popped)
 			}
 
 			if nextTrack == nil {
+				metrics.QueueLength.WithLabelValues(ctx.GetGuildID(), ctx.VoiceChannelID).Set(0)
 				_ = store.SetPlaying(queueKey, false)
 				_ = store.ClearNowPlaying(queueKey)
 
-				context.NowPlayingMutex.Lock()
-				delete(context.NowPlaying, queueKey)
-				context.NowPlayingMutex.Unlock()
-
-				context.NowPlayingInfoMutex.Lock()
-				delete(context.NowPlayingInfo, queueKey)
-				context.NowPlayingInfoMutex.Unlock()
-
-				// Wait a moment before disconnecting to avoid rapid connect/disconnect cycles
-				time.Sleep(500 * time.Millisecond)
+				// Stay connected while idle to avoid rapid connect/disconnect cycles.
+				// Play (new songs queued) or Stop wakes the loop early.
+				idleTimeout := config.IdleTimeout()
+				if idleTimeout < 500*time.Millisecond {
+					idleTimeout = 500 * time.Millisecond
+				}
+				if player.Idle(idleTimeout) {
+					continue
+				}
+				// from here a Play starts a new loop instead of waking this
+				// one, so a track queued before it is caught by the check
+				player.BeginExit()
+				if pending, lengthErr := store.Length(queueKey); lengthErr == nil && pending > 0 {
+					player.CancelExit()
+					continue
+				}
 
+				ctx.Logger().Info("Idle timeout reached, leaving voice channel: " + queueKey)
 				vc, vcErr := discord.GetVoiceConnection(ctx)
 				if vcErr == nil {
-					vc.Speaking(false)
-					vc.Disconnect()
+					discord.Disconnect(vc)
 				}
 				break
 			}
@@ -52,57 +95,126 @@ func ProcessQueue(ctx *context.Context) {
 			meta, metaErr := store.LookupMetadata(queueKey, nextTrack.URL)
 			if metaErr == nil && meta != nil {
 				nextTrack = meta
+			} else {
+				// the background lookup hasn't finished, usually for the first
+				// track. Wait for it here so live streams open as live.
+				nextTrack = resolveTrack(nextTrack)
 			}
 			_ = store.SetNowPlaying(queueKey, nextTrack)
 
-			context.NowPlayingMutex.Lock()
-			context.NowPlaying[queueKey] = nextTrack.URL
-			context.NowPlayingMutex.Unlock()
-
-			context.NowPlayingInfoMutex.Lock()
-			context.NowPlayingInfo[queueKey] = nextTrack
-			context.NowPlayingInfoMutex.Unlock()
-
 			pending, lengthErr := store.Length(queueKey)
 			if lengthErr != nil {
 				pending = 0
 			}
-
-			title := nextTrack.Title
-			if title == "" {
-				title = nextTrack.URL
+			metrics.QueueLength.WithLabelValues(ctx.GetGuildID(), ctx.VoiceChannelID).Set(float64(pending))
+
+			title := trackTitle(nextTrack)
+
+			start := youtube.StartOffset(nextTrack.URL)
+			if seeking {
+				start = seekTo
+				seeking = false
+			} else {
+				ctx.Logger().Info("Playing song", "pending", pending, "queue", queueKey)
+				message := fmt.Sprintf("Now playing: %s%s", title, liveTag(nextTrack))
+				if decoder != nil {
+					// don't hold up a prefetched track for the message. A track
+					// already played, so any interaction has been answered and
+					// this is a plain channel message.
+					go ctx.Reply(message)
+				} else {
+					ctx.Reply(message)
+				}
 			}
 
-			logging.Info(fmt.Sprintf("Playing song, %d more in queue: %s", pending, queueKey))
-			ctx.Reply(fmt.Sprintf("Now playing: %s", title))
+			interrupt := player.StartTrack(nextTrack)
+			_ = store.SetPosition(queueKey, start.Milliseconds(), time.Now().Add(-start))
 
-			// Create a stop channel for this song
-			context.StopMutex.Lock()
-			stop := make(chan bool)
-			context.StopChannels[queueKey] = stop
-			context.StopMutex.Unlock()
+			positionDone := make(chan struct{})
+			go persistPosition(store, queueKey, player, positionDone)
+			playErr := PlayAudio(ctx, player, nextTrack, start, decoder, interrupt)
+			close(positionDone)
+			player.FinishTrack()
 
-			// Create pause channel
-			context.PauseChMutex.Lock()
-			pauseCh := make(chan bool, 1)
-			context.PauseChs[queueKey] = pauseCh
-			context.PauseChMutex.Unlock()
+			if player.Stopping() {
+				break
+			}
 
-			// Initialize pause state
-			context.PauseMutex.Lock()
-			pauseCh <- context.Paused[queueKey]
-			context.PauseMutex.Unlock()
+			// a seek interrupts the track, restart it from the new offset
+			if seekTo, seeking = player.TakeSeek(); seeking {
+				replay = nextTrack
+				continue
+			}
 
-			done := make(chan bool)
-			go PlayAudio(ctx, nextTrack.URL, stop, pauseCh, done)
-			<-done
+			replay = loopTrack(store, queueKey, nextTrack, playErr != nil, interrupted(interrupt))
 
-			logging.Info("Song finished, moving to next in queue if available.")
+			ctx.Logger().Info("Song finished, moving to next in queue if available.")
+		}
+	})
 
-			// Clean up pause channel
-			context.PauseChMutex.Lock()
-			delete(context.PauseChs, queueKey)
-			context.PauseChMutex.Unlock()
+	if !started {
+		ctx.Logger().Info("Player already running for queue: " + queueKey)
+	}
+}
+
+// apply the queue's loop mode to a finished track. Returns the track to
+// replay next, if any. A track loop only repeats tracks that played to the
+// end. A queue loop keeps skipped tracks in the rotation, failed tracks are
+// never re-queued so a broken link can't respawn yt-dlp forever.
+func loopTrack(store context.QueueStore, queueKey string, finished *context.TrackInfo, failed, skipped bool) *context.TrackInfo {
+	mode, err := store.GetLoopMode(queueKey)
+	if err != nil {
+		logging.Error("Failed to read loop mode: " + err.Error())
+		return nil
+	}
+
+	switch mode {
+	case context.LoopTrack:
+		if !failed && !skipped {
+			return finished
 		}
-	}()
+	case context.LoopQueue:
+		if failed {
+			return nil
+		}
+		if err := store.Append(queueKey, finished); err != nil {
+			logging.Error("Failed to re-queue looped track: " + err.Error())
+		}
+	}
+	return nil
+}
+
+// fill in a queued track's metadata from its provider, keeping who added it.
+// The track is returned unchanged when the lookup fails.
+func resolveTrack(track *context.TrackInfo) *context.TrackInfo {
+	info, err := provider.Resolve(track.URL)
+	if err != nil || info == nil {
+		return track
+	}
+
+	resolved := *track
+	resolved.Title = info.Title
+	resolved.Artist = info.Artist
+	resolved.Duration = info.Duration
+	resolved.Thumbnail = info.Thumbnail
+	resolved.Live = info.Live
+	return &resolved
+}
+
+// return the track's title, or its URL when the title is unknown
+func trackTitle(track *context.TrackInfo) string {
+	if track.Title == "" {
+		return track.URL
+	}
+	return track.Title
+}
+
+// report whether the track was skipped or stopped
+func interrupted(interrupt <-chan struct{}) bool {
+	select {
+	case <-interrupt:
+		return true
+	default:
+		return false
+	}
 }
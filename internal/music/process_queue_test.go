@@ -0,0 +1,56 @@
+package music
+
+import (
+	"testing"
+
+	"github.com/ekkolyth/ekko-bot/internal/context"
+)
+
+// loopStore records appends and answers the loop mode, the rest of the
+// QueueStore is left nil since loopTrack doesn't touch it
+type loopStore struct {
+	context.QueueStore
+	mode     context.LoopMode
+	appended []*context.TrackInfo
+}
+
+func (s *loopStore) GetLoopMode(string) (context.LoopMode, error) {
+	return s.mode, nil
+}
+
+func (s *loopStore) Append(_ string, track *context.TrackInfo) error {
+	s.appended = append(s.appended, track)
+	return nil
+}
+
+func TestLoopTrack(t *testing.T) {
+	track := &context.TrackInfo{URL: "https://www.youtube.com/watch?v=dQw4w9WgXcQ"}
+
+	tests := []struct {
+		name         string
+		mode         context.LoopMode
+		failed       bool
+		skipped      bool
+		wantReplay   bool
+		wantAppended int
+	}{
+		{"off completed", context.LoopOff, false, false, false, 0},
+		{"track completed", context.LoopTrack, false, false, true, 0},
+		{"track skipped", context.LoopTrack, false, true, false, 0},
+		{"track failed", context.LoopTrack, true, false, false, 0},
+		{"queue completed", context.LoopQueue, false, false, false, 1},
+		{"queue skipped", context.LoopQueue, false, true, false, 1},
+		{"queue failed", context.LoopQueue, true, false, false, 0},
+	}
+
+	for _, test := range tests {
+		store := &loopStore{mode: test.mode}
+		replay := loopTrack(store, "guild:channel", track, test.failed, test.skipped)
+		if (replay != nil) != test.wantReplay {
+			t.Errorf("%s: replay = %v; want %v", test.name, replay != nil, test.wantReplay)
+		}
+		if len(store.appended) != test.wantAppended {
+			t.Errorf("%s: appended %d tracks; want %d", test.name, len(store.appended), test.wantAppended)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package music
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ekkolyth/ekko-bot/internal/context"
+	"github.com/ekkolyth/ekko-bot/internal/discord"
+)
+
+// SeekSong restarts the current song from a mm:ss (or hh:mm:ss) position
+func SeekSong(ctx *context.Context) {
+	offset, ok := parseTimestamp(ctx.Arguments["position"])
+	if !ok {
+		ctx.Reply("Usage: seek <mm:ss>")
+		return
+	}
+
+	SeekTo(ctx, offset)
+}
+
+// SeekTo restarts the current song from offset, the error is what was replied
+// when it couldn't
+func SeekTo(ctx *context.Context, offset time.Duration) error {
+	if !discord.EnsureVoiceChannelID(ctx) {
+		return replyError(ctx, "Could not determine your voice channel.")
+	}
+
+	queueKey := context.QueueKey(ctx.GetGuildID(), ctx.VoiceChannelID)
+
+	player, ok := context.LookupPlayer(queueKey)
+	if !ok {
+		return replyError(ctx, "Nothing is playing.")
+	}
+
+	nowPlaying := player.State().NowPlaying
+	if nowPlaying == nil {
+		return replyError(ctx, "Nothing is playing.")
+	}
+
+	if nowPlaying.Live {
+		return replyError(ctx, "Can't seek in a live stream.")
+	}
+
+	if nowPlaying.Duration > 0 && offset >= time.Duration(nowPlaying.Duration)*time.Second {
+		return replyError(ctx, fmt.Sprintf("Position is past the end of the song (%s).", formatTimestamp(time.Duration(nowPlaying.Duration)*time.Second)))
+	}
+
+	if !player.Seek(offset) {
+		return replyError(ctx, "Nothing is playing.")
+	}
+
+	ctx.Reply("Seeking to " + formatTimestamp(offset))
+	return nil
+}
+
+// parse "ss", "mm:ss" or "hh:mm:ss"
+func parseTimestamp(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	parts := strings.Split(value, ":")
+	if len(parts) > 3 {
+		return 0, false
+	}
+
+	var offset time.Duration
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || (i > 0 && n > 59) {
+			return 0, false
+		}
+		offset = offset*60 + time.Duration(n)
+	}
+	return offset * time.Second, true
+}
+
+// format a duration as m:ss or h:mm:ss
+func formatTimestamp(d time.Duration) string {
+	total := int(d / time.Second)
+	hours, minutes, seconds := total/3600, (total/60)%60, total%60
+	if hours > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", hours, minutes, seconds)
+	}
+	return fmt.Sprintf("%d:%02d", minutes, seconds)
+}
@@ -0,0 +1,51 @@
+package music
+
+import (
+	"github.com/ekkolyth/ekko-bot/internal/context"
+	"github.com/ekkolyth/ekko-bot/internal/discord"
+)
+
+func SetLoopMode(ctx *context.Context) {
+	if !discord.EnsureVoiceChannelID(ctx) {
+		ctx.Reply("Could not determine your voice channel.")
+		return
+	}
+
+	queueKey := context.QueueKey(ctx.GetGuildID(), ctx.VoiceChannelID)
+	store := context.GetQueueStore()
+	if store == nil {
+		ctx.Reply("Queue store unavailable.")
+		return
+	}
+
+	if ctx.Arguments["mode"] == "" {
+		current, err := store.GetLoopMode(queueKey)
+		if err != nil {
+			ctx.Reply("Failed to read loop mode.")
+			return
+		}
+		ctx.Reply("Loop mode is " + string(current) + ".")
+		return
+	}
+
+	mode, ok := context.ParseLoopMode(ctx.Arguments["mode"])
+	if !ok {
+		ctx.Reply("Invalid loop mode. Use off, track or queue.")
+		return
+	}
+
+	if err := store.SetLoopMode(queueKey, mode); err != nil {
+		ctx.Logger().Error("Failed to set loop mode", "error", err)
+		ctx.Reply("Failed to set loop mode.")
+		return
+	}
+
+	switch mode {
+	case context.LoopTrack:
+		ctx.Reply("Looping the current song.")
+	case context.LoopQueue:
+		ctx.Reply("Looping the queue.")
+	default:
+		ctx.Reply("Looping disabled.")
+	}
+}
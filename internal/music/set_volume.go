@@ -38,12 +38,7 @@ func SetVolume(ctx *context.Context) {
 	// Normalize the volume to a range of 0.0 to 2.0
 	newVolume = newVolume / 100.0 // Convert percentage to a factor
 
-	context.VolumeMutex.Lock()
-	if _, ok := context.Volume[queueKey]; !ok {
-		context.Volume[queueKey] = 1.0 // Initialize to default if not set
-	}
-	context.Volume[queueKey] = newVolume
-	context.VolumeMutex.Unlock()
+	context.GetPlayer(queueKey).SetVolume(newVolume)
 
 	_ = store.SetVolume(queueKey, newVolume)
 
@@ -34,17 +34,22 @@ func ShowQueue(ctx *context.Context) {
 
 	var formattedQueue []string
 	for i, track := range tracks {
-		display := track.Title
-		if display == "" || display == track.URL {
+		if track.Title == "" || track.Title == track.URL {
 			meta, metaErr := store.LookupMetadata(queueKey, track.URL)
-			if metaErr == nil && meta != nil && meta.Title != "" {
-				display = meta.Title
-			} else {
-				display = track.URL
+			if metaErr == nil && meta != nil {
+				track = meta
 			}
 		}
-		formattedQueue = append(formattedQueue, fmt.Sprintf("[%d] %s", i+1, display))
+		formattedQueue = append(formattedQueue, fmt.Sprintf("[%d] %s%s", i+1, trackTitle(track), liveTag(track)))
 	}
 
 	ctx.Reply("Current queue:\n" + strings.Join(formattedQueue, "\n"))
 }
+
+// return a marker for live tracks, which have no duration to show
+func liveTag(track *context.TrackInfo) string {
+	if track.Live {
+		return " [LIVE]"
+	}
+	return ""
+}
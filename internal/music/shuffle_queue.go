@@ -0,0 +1,39 @@
+package music
+
+import (
+	"github.com/ekkolyth/ekko-bot/internal/context"
+	"github.com/ekkolyth/ekko-bot/internal/discord"
+)
+
+func ShuffleQueue(ctx *context.Context) {
+	if !discord.EnsureVoiceChannelID(ctx) {
+		ctx.Reply("Could not determine your voice channel.")
+		return
+	}
+
+	queueKey := context.QueueKey(ctx.GetGuildID(), ctx.VoiceChannelID)
+	store := context.GetQueueStore()
+	if store == nil {
+		ctx.Reply("Queue store unavailable.")
+		return
+	}
+
+	length, err := store.Length(queueKey)
+	if err != nil {
+		ctx.Reply("Failed to load queue.")
+		return
+	}
+
+	if length < 2 {
+		ctx.Reply("Not enough songs in the queue to shuffle.")
+		return
+	}
+
+	if err := store.Shuffle(queueKey); err != nil {
+		ctx.Logger().Error("Failed to shuffle queue", "error", err)
+		ctx.Reply("Failed to shuffle the queue.")
+		return
+	}
+
+	ctx.Reply("Shuffled the queue.")
+}
@@ -0,0 +1,152 @@
+package music
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ekkolyth/ekko-bot/internal/context"
+	"github.com/ekkolyth/ekko-bot/internal/discord"
+	"github.com/ekkolyth/ekko-bot/internal/logging"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+var (
+	// queues to resume once their guild is available, by guild ID
+	pendingResumes      = make(map[string][]string)
+	pendingResumesMutex sync.Mutex
+)
+
+// Shutdown stops every player, saving the current track and position so the
+// queue continues after a restart, then leaves voice. Players that don't stop
+// within timeout are abandoned.
+func Shutdown(s *discordgo.Session, timeout time.Duration) {
+	store := context.GetQueueStore()
+	players := context.Players()
+
+	for _, player := range players {
+		state := player.State()
+		if state.NowPlaying != nil && store != nil {
+			if err := store.SaveResume(player.QueueKey(), state.NowPlaying, state.Position); err != nil {
+				logging.Error("Failed to save interrupted track: " + err.Error())
+			}
+		}
+		player.Stop()
+	}
+
+	deadline := time.Now().Add(timeout)
+	for _, player := range players {
+		queueKey := player.QueueKey()
+		if !player.Wait(time.Until(deadline)) {
+			logging.Warning("Player did not stop before shutdown: " + queueKey)
+		}
+		if store == nil {
+			continue
+		}
+		if err := store.SetPlaying(queueKey, false); err != nil {
+			logging.Error("Failed to update playing state: " + err.Error())
+		}
+		_ = store.SetPaused(queueKey, false)
+		_ = store.ClearNowPlaying(queueKey)
+	}
+
+	s.RLock()
+	connections := make([]*discordgo.VoiceConnection, 0, len(s.VoiceConnections))
+	for _, vc := range s.VoiceConnections {
+		connections = append(connections, vc)
+	}
+	s.RUnlock()
+
+	for _, vc := range connections {
+		if err := discord.Disconnect(vc); err != nil {
+			logging.Error("Error disconnecting from voice channel: " + err.Error())
+		}
+	}
+	logging.Info("Stopped %d players and left %d voice channels", len(players), len(connections))
+}
+
+// ReconcileQueues clears the playing flags left behind by a bot that exited
+// without Shutdown, keeping the track it was playing so the queue can
+// continue. When resume is set the interrupted queues are remembered for
+// ResumeGuild.
+func ReconcileQueues(resume bool) {
+	store := context.GetQueueStore()
+	if store == nil {
+		return
+	}
+
+	playing, err := store.PlayingQueues()
+	if err != nil {
+		logging.Error("Failed to list playing queues: " + err.Error())
+		return
+	}
+	for _, queueKey := range playing {
+		track, err := store.GetNowPlaying(queueKey)
+		if err == nil && track != nil {
+			elapsedMS, _, _ := store.GetPosition(queueKey)
+			if err := store.SaveResume(queueKey, track, time.Duration(elapsedMS)*time.Millisecond); err != nil {
+				logging.Error("Failed to save interrupted track: " + err.Error())
+			}
+		}
+		_ = store.SetPlaying(queueKey, false)
+		_ = store.SetPaused(queueKey, false)
+		_ = store.ClearNowPlaying(queueKey)
+	}
+	if len(playing) > 0 {
+		logging.Info("Reset %d queues left playing by the previous run", len(playing))
+	}
+
+	if !resume {
+		return
+	}
+
+	resumable, err := store.ResumableQueues()
+	if err != nil {
+		logging.Error("Failed to list interrupted queues: " + err.Error())
+		return
+	}
+
+	pendingResumesMutex.Lock()
+	defer pendingResumesMutex.Unlock()
+	for _, queueKey := range resumable {
+		guildID, _, ok := context.SplitQueueKey(queueKey)
+		if !ok {
+			continue
+		}
+		pendingResumes[guildID] = append(pendingResumes[guildID], queueKey)
+	}
+}
+
+// ResumeGuild restarts the guild's interrupted queues once the gateway has
+// delivered the guild. Queues whose voice channel is empty wait for the next
+// play instead.
+func ResumeGuild(s *discordgo.Session, guildID string) {
+	pendingResumesMutex.Lock()
+	queueKeys := pendingResumes[guildID]
+	delete(pendingResumes, guildID)
+	pendingResumesMutex.Unlock()
+
+	store := context.GetQueueStore()
+	for _, queueKey := range queueKeys {
+		_, voiceChannelID, _ := context.SplitQueueKey(queueKey)
+
+		listeners, err := discord.ListenerCount(s, guildID, voiceChannelID)
+		if err != nil || listeners == 0 {
+			logging.Info("Not resuming queue without listeners: " + queueKey)
+			continue
+		}
+
+		if store != nil {
+			_ = store.SetPlaying(queueKey, true)
+		}
+		ProcessQueue(&context.Context{
+			SourceType:     context.SourceTypeSystem,
+			Session:        s,
+			GuildID:        guildID,
+			VoiceChannelID: voiceChannelID,
+			CommandName:    "resume",
+			Arguments:      make(map[string]string),
+			ArgumentsRaw:   make(map[string]any),
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package music
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ekkolyth/ekko-bot/internal/context"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestReconcileQueuesSavesInterruptedTrack(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	store := context.NewRedisQueueStore(client)
+	previous := context.GetQueueStore()
+	context.SetQueueStore(store)
+	t.Cleanup(func() { context.SetQueueStore(previous) })
+
+	queueKey := context.QueueKey("guild", "voice")
+	track := &context.TrackInfo{URL: "https://youtu.be/dQw4w9WgXcQ", Title: "Never Gonna Give You Up"}
+	if err := store.SetPlaying(queueKey, true); err != nil {
+		t.Fatalf("SetPlaying() error = %v", err)
+	}
+	if err := store.SetNowPlaying(queueKey, track); err != nil {
+		t.Fatalf("SetNowPlaying() error = %v", err)
+	}
+	if err := store.SetPosition(queueKey, 42000, time.Now()); err != nil {
+		t.Fatalf("SetPosition() error = %v", err)
+	}
+
+	ReconcileQueues(true)
+	t.Cleanup(func() {
+		pendingResumesMutex.Lock()
+		delete(pendingResumes, "guild")
+		pendingResumesMutex.Unlock()
+	})
+
+	if playing, _ := store.IsPlaying(queueKey); playing {
+		t.Error("queue still marked playing after ReconcileQueues")
+	}
+	if nowPlaying, _ := store.GetNowPlaying(queueKey); nowPlaying != nil {
+		t.Errorf("now playing = %+v after ReconcileQueues; want nil", nowPlaying)
+	}
+
+	pendingResumesMutex.Lock()
+	pending := pendingResumes["guild"]
+	pendingResumesMutex.Unlock()
+	if len(pending) != 1 || pending[0] != queueKey {
+		t.Errorf("pending resumes = %v; want [%s]", pending, queueKey)
+	}
+
+	resumed, position, err := store.TakeResume(queueKey)
+	if err != nil {
+		t.Fatalf("TakeResume() error = %v", err)
+	}
+	if resumed == nil || resumed.URL != track.URL {
+		t.Fatalf("TakeResume() track = %+v; want %+v", resumed, track)
+	}
+	if position != 42*time.Second {
+		t.Errorf("TakeResume() position = %v; want 42s", position)
+	}
+}
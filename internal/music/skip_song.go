@@ -20,12 +20,9 @@ func SkipSong(ctx *context.Context) {
 	queueKey := context.QueueKey(ctx.GetGuildID(), ctx.VoiceChannelID)
 
 	// Signal the current song to stop
-	context.StopMutex.Lock()
-	if stopChan, exists := context.StopChannels[queueKey]; exists {
-		close(stopChan)
-		delete(context.StopChannels, queueKey)
+	if player, ok := context.LookupPlayer(queueKey); ok {
+		player.Skip()
 	}
-	context.StopMutex.Unlock()
 
 	vc.Speaking(false)
 
@@ -0,0 +1,130 @@
+package music
+
+import (
+	stdcontext "context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ekkolyth/ekko-bot/internal/api/httpx"
+	"github.com/ekkolyth/ekko-bot/internal/context"
+	"github.com/ekkolyth/ekko-bot/internal/db"
+)
+
+var radioStationService *db.RadioStationService
+
+// SetRadioStationService wires the stations saved per guild.
+func SetRadioStationService(s *db.RadioStationService) {
+	radioStationService = s
+}
+
+// PlayStation queues the guild's saved station by name
+func PlayStation(ctx *context.Context) {
+	if radioStationService == nil {
+		ctx.Reply("Radio stations are unavailable.")
+		return
+	}
+
+	dbCtx, cancel := stdcontext.WithTimeout(stdcontext.Background(), 2*time.Second)
+	defer cancel()
+
+	name := ctx.Arguments["name"]
+	station, err := radioStationService.GetByName(dbCtx, ctx.GetGuildID(), name)
+	if errors.Is(err, db.ErrRadioStationNotFound) {
+		ctx.Reply(fmt.Sprintf("No station named %s, see %sstations.", name, ctx.CommandPrefix()))
+		return
+	}
+	if err != nil {
+		ctx.Logger().Error("Failed to load radio station", "error", err)
+		ctx.Reply("Failed to load station.")
+		return
+	}
+
+	ctx.Arguments["url"] = station.Url
+	AddSong(ctx, false)
+}
+
+// ListStations shows the guild's saved stations
+func ListStations(ctx *context.Context) {
+	if radioStationService == nil {
+		ctx.Reply("Radio stations are unavailable.")
+		return
+	}
+
+	dbCtx, cancel := stdcontext.WithTimeout(stdcontext.Background(), 2*time.Second)
+	defer cancel()
+
+	stations, err := radioStationService.List(dbCtx, ctx.GetGuildID())
+	if err != nil {
+		ctx.Logger().Error("Failed to list radio stations", "error", err)
+		ctx.Reply("Failed to load stations.")
+		return
+	}
+	if len(stations) == 0 {
+		ctx.Reply(fmt.Sprintf("No stations saved, add one with %ssavestation <name> <url>.", ctx.CommandPrefix()))
+		return
+	}
+
+	lines := make([]string, 0, len(stations))
+	for _, station := range stations {
+		// angle brackets stop Discord embedding every link
+		lines = append(lines, fmt.Sprintf("%s: <%s>", station.Name, station.Url))
+	}
+	ctx.Reply("Saved stations:\n" + strings.Join(lines, "\n"))
+}
+
+// SaveStation saves a stream URL under a name, replacing an existing station
+func SaveStation(ctx *context.Context) {
+	if radioStationService == nil {
+		ctx.Reply("Radio stations are unavailable.")
+		return
+	}
+
+	url := strings.TrimSpace(ctx.Arguments["url"])
+	if !httpx.IsValidURL(url) {
+		ctx.Reply("Invalid URL")
+		return
+	}
+
+	dbCtx, cancel := stdcontext.WithTimeout(stdcontext.Background(), 2*time.Second)
+	defer cancel()
+
+	station, err := radioStationService.Save(dbCtx, ctx.GetGuildID(), ctx.Arguments["name"], url)
+	switch {
+	case errors.Is(err, db.ErrRadioStationNameTooLong):
+		ctx.Reply("Station names can be at most 32 characters.")
+		return
+	case err != nil:
+		ctx.Logger().Error("Failed to save radio station", "error", err)
+		ctx.Reply("Failed to save station.")
+		return
+	}
+
+	ctx.Reply(fmt.Sprintf("Saved station %s, play it with %sradio %s.", station.Name, ctx.CommandPrefix(), station.Name))
+}
+
+// DeleteStation removes a saved station by name
+func DeleteStation(ctx *context.Context) {
+	if radioStationService == nil {
+		ctx.Reply("Radio stations are unavailable.")
+		return
+	}
+
+	dbCtx, cancel := stdcontext.WithTimeout(stdcontext.Background(), 2*time.Second)
+	defer cancel()
+
+	name := ctx.Arguments["name"]
+	err := radioStationService.Delete(dbCtx, ctx.GetGuildID(), name)
+	if errors.Is(err, db.ErrRadioStationNotFound) {
+		ctx.Reply(fmt.Sprintf("No station named %s.", name))
+		return
+	}
+	if err != nil {
+		ctx.Logger().Error("Failed to delete radio station", "error", err)
+		ctx.Reply("Failed to delete station.")
+		return
+	}
+
+	ctx.Reply(fmt.Sprintf("Deleted station %s.", name))
+}
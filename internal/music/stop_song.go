@@ -6,6 +6,8 @@ import (
 	"github.com/ekkolyth/ekko-bot/internal/context"
 	"github.com/ekkolyth/ekko-bot/internal/discord"
 	"github.com/ekkolyth/ekko-bot/internal/logging"
+
+	"github.com/bwmarrin/discordgo"
 )
 
 func StopSong(ctx *context.Context) {
@@ -31,13 +33,15 @@ func StopSong(ctx *context.Context) {
 		return
 	}
 
-	// Signal the current song to stop
-	context.StopMutex.Lock()
-	if stopChan, exists := context.StopChannels[queueKey]; exists {
-		close(stopChan)
-		delete(context.StopChannels, queueKey)
+	stopAndDisconnect(store, queueKey, vc)
+}
+
+// stopAndDisconnect stops the queue's player, clears its state and leaves the voice channel
+func stopAndDisconnect(store context.QueueStore, queueKey string, vc *discordgo.VoiceConnection) {
+	// Signal the current song and the queue loop to stop
+	if player, ok := context.LookupPlayer(queueKey); ok {
+		player.Stop()
 	}
-	context.StopMutex.Unlock()
 
 	// Clear the queue for the guild
 	if err := store.Clear(queueKey); err != nil {
@@ -45,24 +49,13 @@ func StopSong(ctx *context.Context) {
 	}
 
 	// Clear now playing
-	context.NowPlayingMutex.Lock()
-	delete(context.NowPlaying, queueKey)
-	context.NowPlayingMutex.Unlock()
 	_ = store.ClearNowPlaying(queueKey)
 
-	// Clear now playing info
-	context.NowPlayingInfoMutex.Lock()
-	delete(context.NowPlayingInfo, queueKey)
-	context.NowPlayingInfoMutex.Unlock()
-
 	// Clear metadata cache for this queue
 	if err := store.ClearMetadata(queueKey); err != nil {
 		logging.Error("Failed to clear metadata cache: " + err.Error())
 	}
 
-	context.PauseMutex.Lock()
-	delete(context.Paused, queueKey)
-	context.PauseMutex.Unlock()
 	_ = store.SetPaused(queueKey, false)
 
 	// Mark the bot as not playing
@@ -75,9 +68,7 @@ func StopSong(ctx *context.Context) {
 	go func() {
 		// Give a small delay for processes to clean up
 		time.Sleep(500 * time.Millisecond)
-		vc.Speaking(false)
-		err = vc.Disconnect()
-		if err != nil {
+		if err := discord.Disconnect(vc); err != nil {
 			logging.Error("Error disconnecting from voice channel: " + err.Error())
 		}
 	}()
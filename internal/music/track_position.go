@@ -0,0 +1,32 @@
+package music
+
+import (
+	"time"
+
+	"github.com/ekkolyth/ekko-bot/internal/context"
+	"github.com/ekkolyth/ekko-bot/internal/logging"
+)
+
+// persistPosition saves the player's position to the queue store about once a second until done is closed
+func persistPosition(store context.QueueStore, queueKey string, player *context.Player, done <-chan struct{}) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			state := player.State()
+			if state.NowPlaying == nil {
+				continue
+			}
+
+			// the start time moves forward while paused so now - startedAt stays accurate
+			startedAt := time.Now().Add(-state.Position)
+			if err := store.SetPosition(queueKey, state.Position.Milliseconds(), startedAt); err != nil {
+				logging.Error("Failed to save playback position: " + err.Error())
+			}
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package provider
+
+import "strings"
+
+// Bandcamp plays track pages through yt-dlp. Albums are rejected rather than
+// silently playing their first track.
+type Bandcamp struct{}
+
+func (Bandcamp) Name() string {
+	return "bandcamp"
+}
+
+func (Bandcamp) Match(url string) bool {
+	return identify(url) == "bandcamp" && strings.Contains(url, "/track/")
+}
+
+func (Bandcamp) Resolve(url string) (*Metadata, error) {
+	return ytdlpResolve(url)
+}
+
+// yt-dlp has no Bandcamp search extractor
+func (Bandcamp) Search(string) (string, error) {
+	return "", ErrUnsupported
+}
+
+func (Bandcamp) OpenStream(url string) (*Stream, error) {
+	return ytdlpStream(url)
+}
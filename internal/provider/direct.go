@@ -0,0 +1,279 @@
+package provider
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	neturl "net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ekkolyth/ekko-bot/internal/config"
+)
+
+// how long a server may take to answer before its URL is rejected
+const inspectTimeout = 10 * time.Second
+
+// audio file extensions ffmpeg can read straight over HTTP
+var directExtensions = map[string]bool{
+	".mp3":  true,
+	".ogg":  true,
+	".opus": true,
+	".flac": true,
+	".wav":  true,
+	".m4a":  true,
+	".aac":  true,
+}
+
+// playlist extensions of live streams
+var streamExtensions = map[string]bool{
+	".m3u8": true, // HLS
+}
+
+var inspectClient = &http.Client{Transport: fetchTransport, Timeout: inspectTimeout}
+
+// fetches files for playback, which may take longer than inspectTimeout to read
+var streamClient = &http.Client{Transport: fetchTransport}
+
+// Direct plays audio served over HTTP that no other provider claims: files,
+// Discord attachments, Icecast and SHOUTcast radio and HLS streams. Files are
+// piped into ffmpeg, streams are fetched by ffmpeg so it can reconnect. ffmpeg
+// and ffprobe fetch through the proxy of FetchOptions.
+type Direct struct{}
+
+func (Direct) Name() string {
+	return "direct"
+}
+
+// Match accepts links to audio files and HLS playlists, and links without an
+// extension as radio stations use, e.g. http://radio.example.com:8000/live.
+// Check makes sure the latter are audio.
+func (Direct) Match(url string) bool {
+	parsed, err := neturl.Parse(url)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return false
+	}
+	if identify(url) != "" {
+		// e.g. a SoundCloud set, which is a page rather than a stream
+		return false
+	}
+	extension := strings.ToLower(path.Ext(parsed.Path))
+	return extension == "" || directExtensions[extension] || streamExtensions[extension]
+}
+
+// Resolve reads the file's tags and duration, falling back to its file name as
+// title. Radio stations are titled by their icy-name.
+func (Direct) Resolve(url string) (*Metadata, error) {
+	meta := &Metadata{
+		Title: urlTitle(url),
+		URL:   url,
+	}
+
+	source, err := inspect(url)
+	if err != nil {
+		// the file name is still better than the bare URL
+		return meta, nil
+	}
+	if source.Live {
+		meta.Live = true
+		if source.Name != "" {
+			meta.Title = source.Name
+		}
+		return meta, nil
+	}
+
+	info, err := probe(url)
+	if err != nil {
+		return meta, nil
+	}
+	if info.Title != "" {
+		meta.Title = info.Title
+	}
+	meta.Artist = info.Artist
+	meta.Duration = info.Duration
+	return meta, nil
+}
+
+// Check rejects private addresses, pages and files that aren't audio or exceed
+// MAX_FILE_SIZE_MB. Streams the server confirmed as live have no size and are
+// accepted once it answers.
+func (Direct) Check(url string) error {
+	source, err := inspect(url)
+	if err != nil {
+		return err
+	}
+	if source.Live {
+		return nil
+	}
+	if strings.HasPrefix(source.ContentType, "text/") || source.ContentType == "application/json" {
+		return ErrNoAudioStream
+	}
+	if !hasAudioExtension(url) && !audioContentType(source.ContentType) {
+		// nothing but the server says what an extensionless link is
+		return ErrNoAudioStream
+	}
+
+	// OpenStream enforces the limit again while reading, servers may not send
+	// a length or send a wrong one
+	limit := config.MaxFileSize()
+	if source.Length > limit {
+		return fmt.Errorf("%w: %d MB", ErrTooLarge, limit>>20)
+	}
+
+	info, err := probe(url)
+	if err != nil {
+		return err
+	}
+	if info.Size > limit {
+		return fmt.Errorf("%w: %d MB", ErrTooLarge, limit>>20)
+	}
+	return nil
+}
+
+func (Direct) Search(string) (string, error) {
+	return "", ErrUnsupported
+}
+
+// OpenStream fetches the file and pipes it to ffmpeg, so MAX_FILE_SIZE_MB holds
+// while reading even when the server sent no length or a wrong one to Check
+func (Direct) OpenStream(url string) (*Stream, error) {
+	response, err := streamClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode >= http.StatusBadRequest {
+		response.Body.Close()
+		return nil, fmt.Errorf("server returned %s", response.Status)
+	}
+
+	body := response.Body
+	return &Stream{
+		Reader: &maxBytesReader{reader: body, remaining: config.MaxFileSize()},
+		close:  func() { body.Close() },
+	}, nil
+}
+
+// OpenLive hands ffmpeg the URL so it can reconnect when the stream drops,
+// fetching it through the proxy of FetchOptions
+func (Direct) OpenLive(url string) (*Stream, error) {
+	if err := checkHost(url); err != nil {
+		return nil, err
+	}
+	return &Stream{URL: url, Live: true}, nil
+}
+
+// maxBytesReader fails with ErrTooLarge once more than remaining bytes are read
+type maxBytesReader struct {
+	reader    io.Reader
+	remaining int64
+}
+
+func (r *maxBytesReader) Read(buffer []byte) (int, error) {
+	if r.remaining <= 0 {
+		// at the limit, anything more is too much
+		var extra [1]byte
+		n, err := r.reader.Read(extra[:])
+		if n > 0 {
+			return 0, fmt.Errorf("%w: %d MB", ErrTooLarge, config.MaxFileSize()>>20)
+		}
+		return 0, err
+	}
+	if int64(len(buffer)) > r.remaining {
+		buffer = buffer[:r.remaining]
+	}
+	n, err := r.reader.Read(buffer)
+	r.remaining -= int64(n)
+	return n, err
+}
+
+// httpSource is what a server says about a URL before any audio is read
+type httpSource struct {
+	ContentType string
+	Length      int64  // bytes, -1 when the server didn't say
+	Name        string // icy-name of a radio station
+	Live        bool   // the server answered as a radio station or HLS stream
+}
+
+// inspect requests url and reads only the response headers
+func inspect(url string) (*httpSource, error) {
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Icecast and SHOUTcast servers only send icy-* headers when asked
+	request.Header.Set("Icy-MetaData", "1")
+
+	// the connection of the last redirect, which answered the response
+	var conn net.Conn
+	request = request.WithContext(httptrace.WithClientTrace(request.Context(), &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			conn = info.Conn
+		},
+	}))
+
+	response, err := inspectClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	response.Body.Close()
+	icy := answeredICY(conn)
+
+	if response.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("server returned %s", response.Status)
+	}
+
+	contentType, _, _ := mime.ParseMediaType(response.Header.Get("Content-Type"))
+	source := &httpSource{
+		ContentType: contentType,
+		Length:      response.ContentLength,
+		Name:        strings.TrimSpace(response.Header.Get("icy-name")),
+	}
+	source.Live = icy || hasICYHeaders(response.Header) ||
+		strings.HasSuffix(contentType, "mpegurl") // HLS playlists
+	return source, nil
+}
+
+// report whether the response carries any icy-* header
+func hasICYHeaders(header http.Header) bool {
+	for name := range header {
+		if strings.HasPrefix(strings.ToLower(name), "icy-") {
+			return true
+		}
+	}
+	return false
+}
+
+// report whether url ends in an audio file extension
+func hasAudioExtension(url string) bool {
+	parsed, err := neturl.Parse(url)
+	if err != nil {
+		return false
+	}
+	return directExtensions[strings.ToLower(path.Ext(parsed.Path))]
+}
+
+// report whether a content type is audio ffmpeg can decode
+func audioContentType(contentType string) bool {
+	return strings.HasPrefix(contentType, "audio/") || contentType == "application/ogg"
+}
+
+// title a URL by its file name without extension, or its host
+func urlTitle(url string) string {
+	parsed, err := neturl.Parse(url)
+	if err != nil {
+		return url
+	}
+	name := path.Base(parsed.Path)
+	name = strings.TrimSuffix(name, path.Ext(name))
+	if name == "" || name == "." || name == "/" {
+		return parsed.Host
+	}
+	return name
+}
@@ -0,0 +1,200 @@
+package provider
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+)
+
+// let the provider fetch from the test servers on localhost
+func allowLocalhost(t *testing.T) {
+	t.Helper()
+	allowAddress = func(netip.Addr) bool { return true }
+	t.Cleanup(func() { allowAddress = publicAddress })
+}
+
+func TestDirectDetectsRadioFromHeaders(t *testing.T) {
+	allowLocalhost(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(write http.ResponseWriter, read *http.Request) {
+		switch read.URL.Path {
+		case "/live":
+			write.Header().Set("Content-Type", "audio/mpeg")
+			write.Header().Set("icy-name", "Test FM")
+			write.Header().Set("icy-br", "128")
+			write.WriteHeader(http.StatusOK)
+			write.(http.Flusher).Flush()
+		case "/page":
+			write.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = write.Write([]byte("<html></html>"))
+		default:
+			http.NotFound(write, read)
+		}
+	}))
+	defer server.Close()
+
+	meta, err := Direct{}.Resolve(server.URL + "/live")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if !meta.Live || meta.Title != "Test FM" {
+		t.Errorf("Resolve() = %+v; want live track titled Test FM", meta)
+	}
+	if err := (Direct{}).Check(server.URL + "/live"); err != nil {
+		t.Errorf("Check(live) error = %v; want nil", err)
+	}
+
+	if err := (Direct{}).Check(server.URL + "/page"); !errors.Is(err, ErrNoAudioStream) {
+		t.Errorf("Check(page) error = %v; want ErrNoAudioStream", err)
+	}
+	if err := (Direct{}).Check(server.URL + "/missing"); err == nil {
+		t.Error("Check(missing) error = nil; want an error")
+	}
+}
+
+func TestDirectDetectsICYStatusLine(t *testing.T) {
+	allowLocalhost(t)
+
+	// SHOUTcast v1 answers with an ICY status line Go's client doesn't accept
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = http.ReadRequest(bufio.NewReader(conn))
+				_, _ = io.WriteString(conn, "ICY 200 OK\r\nContent-Type: audio/mpeg\r\n\r\n")
+				_, _ = conn.Write(make([]byte, 4096))
+			}()
+		}
+	}()
+
+	url := "http://" + listener.Addr().String() + "/"
+	source, err := inspect(url)
+	if err != nil {
+		t.Fatalf("inspect() error = %v", err)
+	}
+	if !source.Live {
+		t.Errorf("inspect() = %+v; want live", source)
+	}
+	if err := (Direct{}).Check(url); err != nil {
+		t.Errorf("Check() error = %v; want nil", err)
+	}
+}
+
+func TestDirectSizeLimitOnlySkippedWhenLive(t *testing.T) {
+	allowLocalhost(t)
+	t.Setenv("MAX_FILE_SIZE_MB", "1")
+
+	server := httptest.NewServer(http.HandlerFunc(func(write http.ResponseWriter, read *http.Request) {
+		write.Header().Set("Content-Type", "audio/mpeg")
+		switch read.URL.Path {
+		case "/large.mp3":
+			write.Header().Set("Content-Length", strconv.Itoa(2<<20))
+			write.WriteHeader(http.StatusOK)
+		case "/stream":
+			// audio without a length or icy-* headers isn't confirmed live
+			write.WriteHeader(http.StatusOK)
+			write.(http.Flusher).Flush()
+		}
+	}))
+	defer server.Close()
+
+	if err := (Direct{}).Check(server.URL + "/large.mp3"); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Check(large) error = %v; want ErrTooLarge", err)
+	}
+
+	meta, err := Direct{}.Resolve(server.URL + "/stream")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if meta.Live {
+		t.Error("Resolve(stream without icy headers) is live; want a file, limited while reading")
+	}
+}
+
+func TestDirectStreamStopsAtSizeLimit(t *testing.T) {
+	allowLocalhost(t)
+	t.Setenv("MAX_FILE_SIZE_MB", "1")
+
+	server := httptest.NewServer(http.HandlerFunc(func(write http.ResponseWriter, read *http.Request) {
+		// chunked without a length, so only reading can tell the size
+		write.Header().Set("Content-Type", "audio/mpeg")
+		chunk := make([]byte, 64<<10)
+		for sent := 0; sent < 2<<20; sent += len(chunk) {
+			if _, err := write.Write(chunk); err != nil {
+				return
+			}
+			write.(http.Flusher).Flush()
+		}
+	}))
+	defer server.Close()
+
+	stream, err := Direct{}.OpenStream(server.URL + "/song.mp3")
+	if err != nil {
+		t.Fatalf("OpenStream() error = %v", err)
+	}
+	defer stream.Close()
+
+	read, err := io.Copy(io.Discard, stream.Reader)
+	if !errors.Is(err, ErrTooLarge) {
+		t.Errorf("reading the stream error = %v; want ErrTooLarge", err)
+	}
+	if read != 1<<20 {
+		t.Errorf("read %d bytes; want %d", read, 1<<20)
+	}
+}
+
+func TestDirectRefusesPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(write http.ResponseWriter, read *http.Request) {
+		write.Header().Set("Content-Type", "audio/mpeg")
+	}))
+	defer server.Close()
+
+	if err := (Direct{}).Check(server.URL + "/song.mp3"); !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("Check(localhost) error = %v; want ErrPrivateAddress", err)
+	}
+	if _, err := (Direct{}).OpenStream(server.URL + "/song.mp3"); !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("OpenStream(localhost) error = %v; want ErrPrivateAddress", err)
+	}
+	if _, err := (Direct{}).OpenLive("http://127.0.0.1:8000/live"); !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("OpenLive(localhost) error = %v; want ErrPrivateAddress", err)
+	}
+
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"10.0.0.8", false},
+		{"172.16.4.2", false},
+		{"192.168.1.10", false},
+		{"169.254.169.254", false}, // cloud metadata
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::1", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"::ffff:127.0.0.1", false},
+	}
+	for _, test := range tests {
+		if got := publicAddress(netip.MustParseAddr(test.addr)); got != test.want {
+			t.Errorf("publicAddress(%s) = %v; want %v", test.addr, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package provider
+
+import (
+	"bytes"
+	stdctx "context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	neturl "net/url"
+	"syscall"
+)
+
+// ErrPrivateAddress is returned when a URL points at a host that isn't on the
+// public internet, e.g. localhost, the LAN or a cloud metadata service
+var ErrPrivateAddress = errors.New("address is not public")
+
+// reports whether the bot may fetch from ip, replaced in tests that serve
+// from localhost
+var allowAddress = publicAddress
+
+// ProtocolWhitelist is what ffmpeg and ffprobe may open for a URL, never local
+// files or other programs. httpproxy tunnels https through the fetch proxy.
+const ProtocolWhitelist = "http,https,tcp,tls,httpproxy"
+
+// fetchTransport dials only public addresses and reads SHOUTcast's ICY status
+// line. Every request the bot makes for a user's link goes through it.
+var fetchTransport = &http.Transport{
+	DialContext:           dialPublic,
+	ForceAttemptHTTP2:     true,
+	TLSHandshakeTimeout:   inspectTimeout,
+	ResponseHeaderTimeout: inspectTimeout,
+}
+
+// dial address, reading SHOUTcast's status line as HTTP
+func dialPublic(ctx stdctx.Context, network, address string) (net.Conn, error) {
+	conn, err := publicDialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	return &icyConn{Conn: conn}, nil
+}
+
+// publicDialer refuses connections to addresses allowAddress rejects. The
+// check runs on the resolved address right before connecting, so redirects
+// and DNS answers that change between lookups are caught too.
+var publicDialer = &net.Dialer{
+	Timeout: inspectTimeout,
+	Control: func(_, address string, _ syscall.RawConn) error {
+		addrPort, err := netip.ParseAddrPort(address)
+		if err != nil {
+			return err
+		}
+		if !allowAddress(addrPort.Addr()) {
+			return fmt.Errorf("%w: %s", ErrPrivateAddress, addrPort.Addr())
+		}
+		return nil
+	},
+}
+
+// checkHost resolves url's host and rejects it when any of its addresses isn't
+// public. It only answers early with a clear error, ffmpeg's connections are
+// guarded by the fetch proxy.
+func checkHost(url string) error {
+	parsed, err := neturl.Parse(url)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := stdctx.WithTimeout(stdctx.Background(), inspectTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", parsed.Hostname())
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if !allowAddress(addr) {
+			return fmt.Errorf("%w: %s", ErrPrivateAddress, addr)
+		}
+	}
+	return nil
+}
+
+// report whether ip is a public unicast address
+func publicAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsValid() &&
+		!ip.IsUnspecified() &&
+		!ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// carrier-grade NAT range, not reachable from the internet either
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// icyConn rewrites the "ICY 200 OK" status line SHOUTcast v1 servers answer
+// with to HTTP/1.0, which Go's client would otherwise reject, and remembers
+// that it did
+type icyConn struct {
+	net.Conn
+	checked bool
+	pending []byte
+	icy     bool
+}
+
+var icyStatus = []byte("ICY ")
+
+func (c *icyConn) Read(buffer []byte) (int, error) {
+	if !c.checked {
+		c.checked = true
+		head := make([]byte, 0, len(icyStatus))
+		for len(head) < len(icyStatus) {
+			n, err := c.Conn.Read(head[len(head):cap(head)])
+			head = head[:len(head)+n]
+			if err != nil {
+				if len(head) == 0 {
+					return 0, err
+				}
+				break
+			}
+		}
+		if bytes.Equal(head, icyStatus) {
+			c.icy = true
+			head = []byte("HTTP/1.0 ")
+		}
+		c.pending = head
+	}
+
+	if len(c.pending) > 0 {
+		n := copy(buffer, c.pending)
+		c.pending = c.pending[n:]
+		return n, nil
+	}
+	return c.Conn.Read(buffer)
+}
+
+// report whether conn answered with an ICY status line, looking through TLS
+func answeredICY(conn net.Conn) bool {
+	for conn != nil {
+		if icy, ok := conn.(*icyConn); ok {
+			return icy.icy
+		}
+		wrapped, ok := conn.(interface{ NetConn() net.Conn })
+		if !ok {
+			return false
+		}
+		conn = wrapped.NetConn()
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+)
+
+var (
+	fetchProxyOnce sync.Once
+	fetchProxyURL  string
+	fetchProxyErr  error
+)
+
+// FetchOptions returns the ffmpeg and ffprobe input options for a URL. They
+// may only use network protocols, and only through the fetch proxy, so every
+// connection they open, for redirects, HLS segments and reconnects alike, is
+// dialed by the bot and refused for private addresses.
+func FetchOptions() ([]string, error) {
+	fetchProxyOnce.Do(func() {
+		fetchProxyURL, fetchProxyErr = startFetchProxy()
+	})
+	if fetchProxyErr != nil {
+		return nil, fetchProxyErr
+	}
+	return []string{"-protocol_whitelist", ProtocolWhitelist, "-http_proxy", fetchProxyURL}, nil
+}
+
+// start the fetch proxy on a localhost port, it runs until the process exits
+func startFetchProxy() (string, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	server := &http.Server{
+		Handler:           newFetchProxy(),
+		ReadHeaderTimeout: inspectTimeout,
+	}
+	go server.Serve(listener)
+	return "http://" + listener.Addr().String(), nil
+}
+
+// fetchProxy is a forward proxy for ffmpeg and ffprobe. Plain http requests
+// are sent with fetchTransport, https is tunnelled through CONNECT over a
+// connection from publicDialer.
+type fetchProxy struct {
+	forward *httputil.ReverseProxy
+}
+
+func newFetchProxy() *fetchProxy {
+	return &fetchProxy{
+		forward: &httputil.ReverseProxy{
+			// the request already names its target
+			Rewrite:   func(*httputil.ProxyRequest) {},
+			Transport: fetchTransport,
+			// audio is streamed, pass it on as it arrives
+			FlushInterval: -1,
+			ErrorHandler: func(write http.ResponseWriter, _ *http.Request, err error) {
+				http.Error(write, err.Error(), http.StatusBadGateway)
+			},
+		},
+	}
+}
+
+func (p *fetchProxy) ServeHTTP(write http.ResponseWriter, read *http.Request) {
+	if read.Method == http.MethodConnect {
+		p.tunnel(write, read)
+		return
+	}
+	if read.URL.Scheme != "http" || read.URL.Host == "" {
+		http.Error(write, "only absolute http URLs are proxied", http.StatusBadRequest)
+		return
+	}
+	p.forward.ServeHTTP(write, read)
+}
+
+// connect the client to the host it asked for and copy both ways until
+// either side closes
+func (p *fetchProxy) tunnel(write http.ResponseWriter, read *http.Request) {
+	target, err := publicDialer.DialContext(read.Context(), "tcp", read.Host)
+	if err != nil {
+		http.Error(write, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	hijacker, ok := write.(http.Hijacker)
+	if !ok {
+		target.Close()
+		http.Error(write, "tunnelling unsupported", http.StatusInternalServerError)
+		return
+	}
+	client, buffered, err := hijacker.Hijack()
+	if err != nil {
+		target.Close()
+		return
+	}
+	defer client.Close()
+	defer target.Close()
+
+	if _, err := io.WriteString(client, "HTTP/1.1 200 Connection established\r\n\r\n"); err != nil {
+		return
+	}
+	go func() {
+		_, _ = io.Copy(target, buffered)
+		target.Close()
+	}()
+	_, _ = io.Copy(client, target)
+}
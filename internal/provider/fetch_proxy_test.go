@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	neturl "net/url"
+	"testing"
+)
+
+// client fetching through a fetch proxy, trusting the test server's certificate
+func proxiedClient(t *testing.T, target *httptest.Server) *http.Client {
+	t.Helper()
+	proxy := httptest.NewServer(newFetchProxy())
+	t.Cleanup(proxy.Close)
+
+	proxyURL, err := neturl.Parse(proxy.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := target.Client().Transport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyURL(proxyURL)
+	t.Cleanup(transport.CloseIdleConnections)
+	return &http.Client{Transport: transport}
+}
+
+func audioServer(t *testing.T, tls bool) *httptest.Server {
+	t.Helper()
+	handler := http.HandlerFunc(func(write http.ResponseWriter, read *http.Request) {
+		write.Header().Set("Content-Type", "audio/mpeg")
+		_, _ = io.WriteString(write, "ID3")
+	})
+	var server *httptest.Server
+	if tls {
+		server = httptest.NewTLSServer(handler)
+	} else {
+		server = httptest.NewServer(handler)
+	}
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchProxyForwardsToPublicAddresses(t *testing.T) {
+	allowLocalhost(t)
+
+	for _, tls := range []bool{false, true} {
+		server := audioServer(t, tls)
+		response, err := proxiedClient(t, server).Get(server.URL + "/song.mp3")
+		if err != nil {
+			t.Fatalf("Get(tls %v) error = %v", tls, err)
+		}
+		body, _ := io.ReadAll(response.Body)
+		response.Body.Close()
+		if response.StatusCode != http.StatusOK || string(body) != "ID3" {
+			t.Errorf("Get(tls %v) = %d %q; want 200 ID3", tls, response.StatusCode, body)
+		}
+	}
+}
+
+func TestFetchProxyRefusesPrivateAddresses(t *testing.T) {
+	// plain http is answered by the proxy, https fails to tunnel
+	server := audioServer(t, false)
+	response, err := proxiedClient(t, server).Get(server.URL + "/song.mp3")
+	if err != nil {
+		t.Fatalf("Get(http) error = %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusBadGateway {
+		t.Errorf("Get(http localhost) = %d; want %d", response.StatusCode, http.StatusBadGateway)
+	}
+
+	server = audioServer(t, true)
+	if _, err := proxiedClient(t, server).Get(server.URL + "/song.mp3"); err == nil {
+		t.Error("Get(https localhost) error = nil; want the tunnel refused")
+	}
+}
@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/ekkolyth/ekko-bot/internal/youtube"
+)
+
+// how many search results are scored for each track
+const matchCandidates = 5
+
+// results scoring below this are wrong songs more often than not
+const minMatchScore = 30
+
+// words that mark a different recording, unless the track itself has them
+var alternateVersionWords = []string{"live", "cover", "remix", "karaoke", "instrumental", "acoustic", "nightcore", "slowed", "sped", "8d", "reaction"}
+
+// MatchYouTube finds the YouTube video that best fits a track known by its
+// title, artist and duration in seconds (0 when unknown)
+func MatchYouTube(title, artist string, duration int) (*Metadata, error) {
+	query := strings.TrimSpace(artist + " - " + title)
+	candidates, err := youtube.SearchVideos(query, matchCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	var best *youtube.VideoInfo
+	bestScore := minMatchScore - 1.0
+	for i := range candidates {
+		if score := scoreCandidate(title, artist, duration, candidates[i]); score > bestScore {
+			best, bestScore = &candidates[i], score
+		}
+	}
+	if best == nil {
+		return nil, ErrNoResults
+	}
+
+	return &Metadata{
+		Title:     best.Title,
+		URL:       best.URL,
+		Artist:    best.Artist,
+		Duration:  best.Duration,
+		Thumbnail: best.Thumbnail,
+	}, nil
+}
+
+// score how likely candidate is the same recording, roughly 0-100. Title and
+// artist words count for 60, the duration for 30 and is heavily penalised
+// when far off, since a wrong length usually means a different version.
+func scoreCandidate(title, artist string, duration int, candidate youtube.VideoInfo) float64 {
+	titleWords := words(title)
+	candidateTitle := words(candidate.Title)
+	candidateAll := append(words(candidate.Artist), candidateTitle...)
+
+	score := 40*overlap(titleWords, candidateTitle) + 20*overlap(words(artist), candidateAll)
+
+	if duration > 0 && candidate.Duration > 0 {
+		diff := duration - candidate.Duration
+		if diff < 0 {
+			diff = -diff
+		}
+		switch {
+		case diff <= 3:
+			score += 30
+		case diff <= 30:
+			score += 30 - float64(diff)
+		default:
+			score -= 50
+		}
+	}
+
+	// auto-generated "Artist - Topic" channels carry the album audio itself
+	if strings.HasSuffix(candidate.Artist, " - Topic") {
+		score += 10
+	}
+
+	for _, word := range alternateVersionWords {
+		if contains(candidateTitle, word) && !contains(titleWords, word) {
+			score -= 15
+		}
+	}
+	return score
+}
+
+// split text into lowercase letter and digit runs
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// return the fraction of want found in have, 1 when want is empty
+func overlap(want, have []string) float64 {
+	if len(want) == 0 {
+		return 1
+	}
+	found := 0
+	for _, word := range want {
+		if contains(have, word) {
+			found++
+		}
+	}
+	return float64(found) / float64(len(want))
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
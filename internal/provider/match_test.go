@@ -0,0 +1,29 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/ekkolyth/ekko-bot/internal/youtube"
+)
+
+func TestScoreCandidatePrefersMatchingDuration(t *testing.T) {
+	candidates := []youtube.VideoInfo{
+		{Title: "Artist - Song (Live at Wembley)", Artist: "Artist", Duration: 412},
+		{Title: "Song", Artist: "Artist - Topic", Duration: 215},
+		{Title: "Artist - Song (Official Video)", Artist: "ArtistVEVO", Duration: 268},
+	}
+
+	best, bestScore := -1, 0.0
+	for i, candidate := range candidates {
+		if score := scoreCandidate("Song", "Artist", 214, candidate); best == -1 || score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best != 1 {
+		t.Fatalf("best candidate = %q; want the Topic upload with the right length", candidates[best].Title)
+	}
+
+	if score := scoreCandidate("Song", "Artist", 214, candidates[0]); score >= minMatchScore {
+		t.Errorf("live version scored %.1f; want below %d", score, minMatchScore)
+	}
+}
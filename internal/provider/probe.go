@@ -0,0 +1,103 @@
+package provider
+
+import (
+	stdctx "context"
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ekkolyth/ekko-bot/internal/metrics"
+)
+
+// how long ffprobe may take to read a remote file's headers
+const probeTimeout = 15 * time.Second
+
+// ErrNoAudioStream is returned when a file has no audio ffmpeg can decode
+var ErrNoAudioStream = errors.New("file has no audio stream")
+
+// probed describes an audio file as ffprobe sees it
+type probed struct {
+	Title    string
+	Artist   string
+	Duration int   // seconds, 0 when unknown
+	Size     int64 // bytes, 0 when the server didn't say
+}
+
+// probe reads a file's container metadata without decoding it
+func probe(url string) (*probed, error) {
+	fetchOptions, err := FetchOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := stdctx.WithTimeout(stdctx.Background(), probeTimeout)
+	defer cancel()
+
+	args := append([]string{"-v", "error"}, fetchOptions...)
+	args = append(args,
+		"-show_entries", "format=duration,size:format_tags:stream=codec_type:stream_tags",
+		"-of", "json",
+		url,
+	)
+	cmd := exec.CommandContext(ctx, "ffprobe", args...)
+	metrics.ProcessSpawnsTotal.WithLabelValues("ffprobe").Inc()
+	started := time.Now()
+	output, err := cmd.Output()
+	metrics.ObserveProcess("ffprobe", started, err)
+	metrics.ObserveMetadata("probe", started, err)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw struct {
+		Format struct {
+			Duration string            `json:"duration"`
+			Size     string            `json:"size"`
+			Tags     map[string]string `json:"tags"`
+		} `json:"format"`
+		Streams []struct {
+			CodecType string            `json:"codec_type"`
+			Tags      map[string]string `json:"tags"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(output, &raw); err != nil {
+		return nil, err
+	}
+
+	// Ogg keeps its tags on the stream, most other containers on the format
+	tags := []map[string]string{raw.Format.Tags}
+	hasAudio := false
+	for _, stream := range raw.Streams {
+		if stream.CodecType == "audio" {
+			hasAudio = true
+			tags = append(tags, stream.Tags)
+		}
+	}
+	if !hasAudio {
+		return nil, ErrNoAudioStream
+	}
+
+	seconds, _ := strconv.ParseFloat(raw.Format.Duration, 64)
+	size, _ := strconv.ParseInt(raw.Format.Size, 10, 64)
+	return &probed{
+		Title:    tag(tags, "title"),
+		Artist:   tag(tags, "artist"),
+		Duration: int(seconds),
+		Size:     size,
+	}, nil
+}
+
+// return the first value of a tag, tag names differ in case between formats
+func tag(sets []map[string]string, name string) string {
+	for _, set := range sets {
+		for key, value := range set {
+			if strings.EqualFold(key, name) && strings.TrimSpace(value) != "" {
+				return strings.TrimSpace(value)
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,175 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrUnsupported is returned when a provider can't do what was asked, e.g. search
+var ErrUnsupported = errors.New("not supported by this provider")
+
+// ErrNoMatch is returned when no registered provider accepts a URL
+var ErrNoMatch = errors.New("no provider for url")
+
+// ErrNoResults is returned when a search finds nothing
+var ErrNoResults = errors.New("no results")
+
+// ErrTooLarge is returned by Check for files over the size limit
+var ErrTooLarge = errors.New("file exceeds the size limit")
+
+// DefaultSearch is the provider that answers /search
+const DefaultSearch = "youtube"
+
+// Metadata describes a resolved track
+type Metadata struct {
+	Title     string
+	URL       string
+	Artist    string
+	Duration  int // seconds, 0 when unknown
+	Thumbnail string
+	Live      bool // a live stream or radio station, it has no end
+}
+
+// Provider resolves, searches and streams tracks from one media source
+type Provider interface {
+	// Name identifies the provider in logs and API responses
+	Name() string
+	// Match reports whether the provider can play url
+	Match(url string) bool
+	// Resolve fetches metadata for a matched url
+	Resolve(url string) (*Metadata, error)
+	// Search returns the URL of the best result for query, or ErrUnsupported
+	Search(query string) (string, error)
+	// OpenStream starts fetching the audio of a matched url
+	OpenStream(url string) (*Stream, error)
+}
+
+// Normalizer is implemented by providers that canonicalise their URLs before queueing
+type Normalizer interface {
+	Normalize(url string) (string, bool)
+}
+
+// LiveOpener is implemented by providers that open live sources differently,
+// e.g. handing ffmpeg a URL it can reconnect to instead of piping yt-dlp
+type LiveOpener interface {
+	OpenLive(url string) (*Stream, error)
+}
+
+// Checker is implemented by providers that must inspect a URL before it is
+// queued, e.g. to enforce a size limit
+type Checker interface {
+	Check(url string) error
+}
+
+var (
+	providers      []Provider
+	providersMutex sync.RWMutex
+)
+
+func init() {
+	// the direct provider accepts any http(s) URL, so it goes last
+	Register(YouTube{}, Spotify{}, SoundCloud{}, Bandcamp{}, Direct{})
+}
+
+// Register adds providers to the registry, earlier providers win when several match
+func Register(list ...Provider) {
+	providersMutex.Lock()
+	defer providersMutex.Unlock()
+
+	providers = append(providers, list...)
+}
+
+// Lookup returns the first provider that matches url
+func Lookup(url string) (Provider, bool) {
+	url = strings.TrimSpace(url)
+
+	providersMutex.RLock()
+	defer providersMutex.RUnlock()
+
+	for _, provider := range providers {
+		if provider.Match(url) {
+			return provider, true
+		}
+	}
+	return nil, false
+}
+
+// Get returns the provider registered under name
+func Get(name string) (Provider, bool) {
+	providersMutex.RLock()
+	defer providersMutex.RUnlock()
+
+	for _, provider := range providers {
+		if provider.Name() == name {
+			return provider, true
+		}
+	}
+	return nil, false
+}
+
+// Normalize returns the canonical form of url and whether any provider accepts it
+func Normalize(url string) (string, bool) {
+	url = strings.TrimSpace(url)
+	provider, ok := Lookup(url)
+	if !ok {
+		return "", false
+	}
+	if normalizer, ok := provider.(Normalizer); ok {
+		return normalizer.Normalize(url)
+	}
+	return url, true
+}
+
+// Check runs the matching provider's checks, nil when it has none
+func Check(url string) error {
+	provider, ok := Lookup(url)
+	if !ok {
+		return ErrNoMatch
+	}
+	if checker, ok := provider.(Checker); ok {
+		return checker.Check(url)
+	}
+	return nil
+}
+
+// Resolve fetches metadata for url from the provider that matches it
+func Resolve(url string) (*Metadata, error) {
+	provider, ok := Lookup(url)
+	if !ok {
+		return nil, ErrNoMatch
+	}
+	return provider.Resolve(url)
+}
+
+// OpenStream starts the audio of url from the provider that matches it. live
+// is whether Resolve reported the track as live.
+func OpenStream(url string, live bool) (*Stream, error) {
+	provider, ok := Lookup(url)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrNoMatch, url)
+	}
+	if opener, ok := provider.(LiveOpener); ok && live {
+		return opener.OpenLive(url)
+	}
+	return provider.OpenStream(url)
+}
+
+// Search asks the named provider for query. Results that no longer match
+// the provider are discarded.
+func Search(name, query string) (string, error) {
+	provider, ok := Get(name)
+	if !ok {
+		return "", fmt.Errorf("unknown provider %q", name)
+	}
+
+	url, err := provider.Search(query)
+	if err != nil {
+		return "", err
+	}
+	if !provider.Match(url) {
+		return "", fmt.Errorf("%s returned an unplayable result %q", name, url)
+	}
+	return url, nil
+}
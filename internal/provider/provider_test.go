@@ -0,0 +1,42 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/ekkolyth/ekko-bot/internal/lua"
+)
+
+func TestLookupDispatchesByURL(t *testing.T) {
+	if err := lua.Init(); err != nil {
+		t.Fatalf("lua.Init() error = %v", err)
+	}
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", "youtube"},
+		{"https://youtu.be/dQw4w9WgXcQ", "youtube"},
+		{"https://soundcloud.com/artist/some-track", "soundcloud"},
+		{"https://soundcloud.com/artist/sets/some-album", ""},
+		{"https://artist.bandcamp.com/track/some-track", "bandcamp"},
+		{"https://artist.bandcamp.com/album/some-album", ""},
+		{"https://example.com/music/song.MP3?token=abc", "direct"},
+		{"https://example.com/page.html", ""},
+		{"http://radio.example.com:8000/live", "direct"},
+		{"https://example.com/live/stream.m3u8", "direct"},
+		{"ftp://example.com/song.mp3", ""},
+		{"https://open.spotify.com/intl-de/track/abc?si=123", "spotify"},
+		{"https://open.spotify.com/artist/abc", ""},
+	}
+
+	for _, test := range tests {
+		got := ""
+		if provider, ok := Lookup(test.url); ok {
+			got = provider.Name()
+		}
+		if got != test.want {
+			t.Errorf("Lookup(%q) = %q; want %q", test.url, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package provider
+
+import "strings"
+
+// SoundCloud plays single tracks through yt-dlp. Sets are rejected rather
+// than silently playing their first track.
+type SoundCloud struct{}
+
+func (SoundCloud) Name() string {
+	return "soundcloud"
+}
+
+func (SoundCloud) Match(url string) bool {
+	return identify(url) == "soundcloud" && !strings.Contains(url, "/sets/")
+}
+
+func (SoundCloud) Resolve(url string) (*Metadata, error) {
+	return ytdlpResolve(url)
+}
+
+func (SoundCloud) Search(query string) (string, error) {
+	return ytdlpSearch("scsearch1", query)
+}
+
+func (SoundCloud) OpenStream(url string) (*Stream, error) {
+	return ytdlpStream(url)
+}
@@ -0,0 +1,75 @@
+package provider
+
+import (
+	stdctx "context"
+	"errors"
+
+	"github.com/ekkolyth/ekko-bot/internal/spotify"
+)
+
+// Spotify reads track metadata from the Web API and plays the best matching
+// YouTube audio, Spotify itself can't be streamed
+type Spotify struct{}
+
+func (Spotify) Name() string {
+	return "spotify"
+}
+
+func (Spotify) Match(url string) bool {
+	if identify(url) != "spotify" {
+		return false
+	}
+	_, _, ok := spotify.ParseURL(url)
+	return ok
+}
+
+// Resolve returns a track's Spotify metadata, or an album or playlist's name
+func (Spotify) Resolve(url string) (*Metadata, error) {
+	collection, err := spotify.Default().Resolve(stdctx.Background(), url, 1)
+	if err != nil {
+		return nil, err
+	}
+	if collection.Kind != "track" {
+		return &Metadata{Title: collection.Title, URL: url}, nil
+	}
+
+	track := collection.Tracks[0]
+	return &Metadata{
+		Title:     track.Title,
+		URL:       track.URL,
+		Artist:    track.Artist(),
+		Duration:  track.Duration,
+		Thumbnail: track.Thumbnail,
+	}, nil
+}
+
+func (Spotify) Search(string) (string, error) {
+	return "", ErrUnsupported
+}
+
+// OpenStream plays the YouTube match of a track link. Albums and playlists
+// are expanded into tracks when queued and never reach playback.
+func (provider Spotify) OpenStream(url string) (*Stream, error) {
+	if kind, _, _ := spotify.ParseURL(url); kind != "track" {
+		return nil, errors.New("only spotify tracks can be streamed")
+	}
+
+	track, err := provider.Resolve(url)
+	if err != nil {
+		return nil, err
+	}
+	match, err := MatchYouTube(track.Title, track.Artist, track.Duration)
+	if err != nil {
+		return nil, err
+	}
+	return ytdlpStream(match.URL)
+}
+
+// Normalize drops locale prefixes and tracking parameters
+func (Spotify) Normalize(url string) (string, bool) {
+	kind, id, ok := spotify.ParseURL(url)
+	if !ok {
+		return "", false
+	}
+	return spotify.CanonicalURL(kind, id), true
+}
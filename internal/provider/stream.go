@@ -0,0 +1,20 @@
+package provider
+
+import "io"
+
+// Stream is encoded audio for ffmpeg to decode. Either Reader is piped into
+// ffmpeg or ffmpeg fetches URL itself.
+type Stream struct {
+	Reader io.Reader
+	URL    string
+	Live   bool // no end, ffmpeg reconnects rather than finishing the track
+
+	close func()
+}
+
+// Close stops whatever is producing the stream
+func (s *Stream) Close() {
+	if s.close != nil {
+		s.close()
+	}
+}
@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"github.com/ekkolyth/ekko-bot/internal/logging"
+	"github.com/ekkolyth/ekko-bot/internal/lua"
+
+	luaLib "github.com/yuin/gopher-lua"
+)
+
+// YouTube plays videos through yt-dlp and is the default search provider
+type YouTube struct{}
+
+func (YouTube) Name() string {
+	return "youtube"
+}
+
+func (YouTube) Match(url string) bool {
+	return identify(url) == "youtube"
+}
+
+func (YouTube) Resolve(url string) (*Metadata, error) {
+	return ytdlpResolve(url)
+}
+
+func (YouTube) Search(query string) (string, error) {
+	return ytdlpSearch("ytsearch1", query)
+}
+
+func (YouTube) OpenStream(url string) (*Stream, error) {
+	return ytdlpStream(url)
+}
+
+func (YouTube) OpenLive(url string) (*Stream, error) {
+	return ytdlpLive(url)
+}
+
+// Normalize rewrites a video URL to its canonical watch form, keeping the
+// start time. Shorts and malformed video IDs are rejected.
+func (YouTube) Normalize(url string) (string, bool) {
+	script := lua.Get()
+	if err := script.LoadScript("lua/scripts/validate_url/validate_youtube_url.lua"); err != nil {
+		logging.Error("Failed to load normalization script: " + err.Error() + " - URL: " + url)
+		return "", false
+	}
+
+	results, err := script.CallLuaFunc("validate_youtube_url", "normalize_youtube_url", 2, luaLib.LString(url))
+	if err != nil {
+		logging.Error("Failed to normalize URL: " + err.Error() + " - URL: " + url)
+		return "", false
+	}
+
+	if results[1].Type() != luaLib.LTNil {
+		logging.Error("Lua normalization error code: " + results[1].String() + " - URL: " + url)
+		return "", false
+	}
+
+	if results[0].Type() == luaLib.LTNil {
+		logging.Error("Nil result from normalization - URL: " + url)
+		return "", false
+	}
+
+	return results[0].String(), true
+}
@@ -0,0 +1,160 @@
+package provider
+
+import (
+	"bytes"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ekkolyth/ekko-bot/internal/logging"
+	"github.com/ekkolyth/ekko-bot/internal/lua"
+	"github.com/ekkolyth/ekko-bot/internal/metrics"
+	"github.com/ekkolyth/ekko-bot/internal/youtube"
+
+	luaLib "github.com/yuin/gopher-lua"
+)
+
+// identify returns the site validate_url.lua recognises url as, empty if none
+func identify(url string) string {
+	script := lua.Get()
+	if err := script.LoadScript("lua/scripts/validate_url/validate_url.lua"); err != nil {
+		return ""
+	}
+
+	results, err := script.CallLuaFunc("validate_url", "validate_url", 3, luaLib.LString(url))
+	if err != nil {
+		return ""
+	}
+
+	if results[0] == luaLib.LFalse || results[0] == luaLib.LNil || results[1].Type() == luaLib.LTNil {
+		return ""
+	}
+	return results[1].String()
+}
+
+// resolve metadata for any site yt-dlp supports
+func ytdlpResolve(url string) (*Metadata, error) {
+	info, err := youtube.GetVideoInfo(url)
+	if err != nil {
+		return nil, err
+	}
+	return &Metadata{
+		Title:     info.Title,
+		URL:       info.URL,
+		Artist:    info.Artist,
+		Duration:  info.Duration,
+		Thumbnail: info.Thumbnail,
+		Live:      info.Live,
+	}, nil
+}
+
+// search one of yt-dlp's search extractors, e.g. "scsearch1"
+func ytdlpSearch(extractor, query string) (string, error) {
+	url, found := youtube.Search(extractor, query)
+	if !found {
+		return "", ErrNoResults
+	}
+	return url, nil
+}
+
+// stream the best audio yt-dlp can find for url through a pipe
+func ytdlpStream(url string) (*Stream, error) {
+	cmd := exec.Command("yt-dlp",
+		"-f", "bestaudio",
+		"--no-playlist",
+		"-o", "-",
+		url) // Get only audio, best quality
+
+	// Capture stderr for error logging
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+
+	// exec copies into the pipe, so Wait only returns once the reader has drained it
+	reader, writer := io.Pipe()
+	cmd.Stdout = writer
+
+	metrics.ProcessSpawnsTotal.WithLabelValues("yt-dlp").Inc()
+	started := time.Now()
+	if err := cmd.Start(); err != nil {
+		metrics.ProcessFailuresTotal.WithLabelValues("yt-dlp").Inc()
+		logging.Error("yt-dlp Start Error: " + err.Error())
+		return nil, err
+	}
+
+	var closeOnce sync.Once
+	var closed bool
+	var closedMutex sync.Mutex
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		err := cmd.Wait()
+		writer.Close()
+
+		// processes killed by Close exit with an error too, that isn't a failure
+		closedMutex.Lock()
+		if closed {
+			err = nil
+		}
+		closedMutex.Unlock()
+
+		metrics.ObserveProcess("yt-dlp", started, err)
+		if err != nil {
+			if stderr.Len() > 0 {
+				logging.Error("yt-dlp failed: " + stderr.String())
+			} else {
+				logging.Error("yt-dlp process exited with error: " + err.Error())
+			}
+		}
+	}()
+
+	return &Stream{
+		Reader: reader,
+		close: func() {
+			closeOnce.Do(func() {
+				closedMutex.Lock()
+				closed = true
+				closedMutex.Unlock()
+
+				cmd.Process.Kill()
+				reader.Close()
+			})
+			// Wait for Wait() to complete to avoid waitid errors
+			select {
+			case <-done:
+			case <-time.After(1 * time.Second):
+			}
+		},
+	}, nil
+}
+
+// ask yt-dlp for the media URL of a live stream so ffmpeg can follow the HLS
+// playlist and reconnect itself, piping would end the track on the first drop
+func ytdlpLive(url string) (*Stream, error) {
+	cmd := exec.Command("yt-dlp",
+		"-f", "bestaudio/best",
+		"--no-playlist",
+		"-g",
+		url)
+
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+
+	metrics.ProcessSpawnsTotal.WithLabelValues("yt-dlp").Inc()
+	started := time.Now()
+	output, err := cmd.Output()
+	metrics.ObserveProcess("yt-dlp", started, err)
+	if err != nil {
+		logging.Error("yt-dlp failed to find live stream: " + stderr.String())
+		return nil, err
+	}
+
+	// -g prints one URL per selected format, the first is the audio
+	mediaURL, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	if mediaURL == "" {
+		return nil, ErrNoAudioStream
+	}
+	return &Stream{URL: mediaURL, Live: true}, nil
+}
@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	stdctx "context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/ekkolyth/ekko-bot/internal/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Result describes the bucket after a request was counted
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next request is allowed, zero when allowed
+}
+
+// Limiter is a token bucket shared by every API replica through redis
+type Limiter struct {
+	client *redis.Client
+}
+
+// NewLimiter returns a limiter storing buckets in redis
+func NewLimiter(client *redis.Client) *Limiter {
+	return &Limiter{client: client}
+}
+
+// refill and take one token atomically, using the redis clock so replicas agree.
+// Returns whether the request is allowed and the tokens left as a string since
+// redis truncates Lua numbers to integers.
+var takeScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local clock = redis.call('TIME')
+local now = tonumber(clock[1]) * 1000 + math.floor(tonumber(clock[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+if now > ts then
+	tokens = math.min(capacity, tokens + (now - ts) * rate)
+end
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil((capacity - tokens) / rate) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+// Take counts one request for key against budget
+func (l *Limiter) Take(ctx stdctx.Context, budget config.RateBudget, key string) (Result, error) {
+	result := Result{Allowed: true, Limit: budget.Burst, Remaining: budget.Burst}
+	if budget.PerMinute <= 0 {
+		return result, nil
+	}
+
+	// tokens per millisecond
+	rate := float64(budget.PerMinute) / float64(time.Minute/time.Millisecond)
+
+	values, err := takeScript.Run(ctx, l.client, []string{bucketKey(budget.Name, key)}, budget.Burst, rate).Slice()
+	if err != nil {
+		return result, fmt.Errorf("rate limit %s: %w", budget.Name, err)
+	}
+	if len(values) != 2 {
+		return result, fmt.Errorf("rate limit %s: unexpected reply %v", budget.Name, values)
+	}
+
+	allowed, _ := values[0].(int64)
+	raw, _ := values[1].(string)
+	tokens, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return result, fmt.Errorf("rate limit %s: %w", budget.Name, err)
+	}
+
+	result.Allowed = allowed == 1
+	result.Remaining = int(math.Floor(tokens))
+	result.Reset = refillTime(float64(budget.Burst)-tokens, rate)
+	if !result.Allowed {
+		result.RetryAfter = refillTime(1-tokens, rate)
+	}
+	return result, nil
+}
+
+// return how long the bucket takes to gain the given tokens
+func refillTime(tokens, rate float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(tokens/rate)) * time.Millisecond
+}
+
+// return bucket key
+func bucketKey(budget, key string) string {
+	return "ratelimit:" + budget + ":" + key
+}
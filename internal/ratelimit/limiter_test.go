@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	stdctx "context"
+	"testing"
+	"time"
+
+	"github.com/ekkolyth/ekko-bot/internal/config"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestLimiter(t *testing.T) (*Limiter, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	// TIME answers the fixed clock once set
+	server.SetTime(time.Unix(1_700_000_000, 0))
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewLimiter(client), server
+}
+
+func TestTakeExhaustsBurst(t *testing.T) {
+	limiter, _ := newTestLimiter(t)
+	budget := config.RateBudget{Name: "write", Burst: 3, PerMinute: 60}
+
+	for i := range 3 {
+		result, err := limiter.Take(stdctx.Background(), budget, "user:1")
+		if err != nil {
+			t.Fatalf("Take() error = %v", err)
+		}
+		if !result.Allowed || result.Remaining != 2-i {
+			t.Fatalf("Take() #%d = %+v; want allowed with %d remaining", i+1, result, 2-i)
+		}
+	}
+
+	result, err := limiter.Take(stdctx.Background(), budget, "user:1")
+	if err != nil {
+		t.Fatalf("Take() error = %v", err)
+	}
+	if result.Allowed {
+		t.Fatal("Take() past the burst allowed; want denied")
+	}
+	// one token a second
+	if result.RetryAfter != time.Second {
+		t.Errorf("RetryAfter = %v; want 1s", result.RetryAfter)
+	}
+	if result.Reset != 3*time.Second {
+		t.Errorf("Reset = %v; want 3s", result.Reset)
+	}
+
+	// other callers have buckets of their own
+	if result, _ := limiter.Take(stdctx.Background(), budget, "user:2"); !result.Allowed {
+		t.Error("Take() for another key denied; want allowed")
+	}
+}
+
+func TestTakeRefills(t *testing.T) {
+	limiter, server := newTestLimiter(t)
+	budget := config.RateBudget{Name: "write", Burst: 2, PerMinute: 60}
+	now := time.Unix(1_700_000_000, 0)
+
+	for range 2 {
+		if _, err := limiter.Take(stdctx.Background(), budget, "user:1"); err != nil {
+			t.Fatalf("Take() error = %v", err)
+		}
+	}
+	if result, _ := limiter.Take(stdctx.Background(), budget, "user:1"); result.Allowed {
+		t.Fatal("Take() on an empty bucket allowed; want denied")
+	}
+
+	server.SetTime(now.Add(1500 * time.Millisecond))
+	result, err := limiter.Take(stdctx.Background(), budget, "user:1")
+	if err != nil {
+		t.Fatalf("Take() error = %v", err)
+	}
+	if !result.Allowed || result.Remaining != 0 {
+		t.Errorf("Take() after 1.5s = %+v; want allowed with 0 remaining", result)
+	}
+
+	// never more than the burst, however long the caller was away
+	server.SetTime(now.Add(time.Hour))
+	result, _ = limiter.Take(stdctx.Background(), budget, "user:1")
+	if !result.Allowed || result.Remaining != 1 {
+		t.Errorf("Take() after an hour = %+v; want allowed with 1 remaining", result)
+	}
+}
+
+func TestTakeUnlimitedBudget(t *testing.T) {
+	limiter, server := newTestLimiter(t)
+	budget := config.RateBudget{Name: "read", Burst: 1, PerMinute: 0}
+
+	for range 5 {
+		if result, err := limiter.Take(stdctx.Background(), budget, "user:1"); err != nil || !result.Allowed {
+			t.Fatalf("Take() = %+v, %v; want allowed", result, err)
+		}
+	}
+	if keys := server.Keys(); len(keys) != 0 {
+		t.Errorf("stored %v for an unlimited budget; want nothing", keys)
+	}
+}
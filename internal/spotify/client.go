@@ -0,0 +1,304 @@
+package spotify
+
+import (
+	stdctx "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ekkolyth/ekko-bot/internal/config"
+	"github.com/ekkolyth/ekko-bot/internal/metrics"
+)
+
+// ErrNotConfigured is returned when SPOTIFY_CLIENT_ID or SPOTIFY_CLIENT_SECRET is missing
+var ErrNotConfigured = errors.New("spotify credentials not configured")
+
+// Track is one Spotify track
+type Track struct {
+	Title     string
+	Artists   []string
+	Duration  int // seconds
+	Thumbnail string
+	URL       string // open.spotify.com link
+}
+
+// Artist returns the track's artists as one credit
+func (track Track) Artist() string {
+	return strings.Join(track.Artists, ", ")
+}
+
+// Collection is what a Spotify link resolved to
+type Collection struct {
+	Kind   string // track, album or playlist
+	Title  string
+	Total  int     // tracks in the album or playlist, may exceed len(Tracks)
+	Tracks []Track // capped at the requested limit
+}
+
+// Client reads tracks from the Spotify Web API with client credentials
+type Client struct {
+	settings config.SpotifySettings
+	http     *http.Client
+
+	tokenMutex sync.Mutex
+	token      string
+	expires    time.Time
+}
+
+var (
+	defaultClient *Client
+	defaultOnce   sync.Once
+)
+
+// NewClient returns a client for the given settings
+func NewClient(settings config.SpotifySettings) *Client {
+	return &Client{
+		settings: settings,
+		http:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Default returns the client configured from the environment
+func Default() *Client {
+	defaultOnce.Do(func() {
+		defaultClient = NewClient(config.Spotify())
+	})
+	return defaultClient
+}
+
+// Resolve reads the tracks behind a Spotify link, at most limit of them
+func (client *Client) Resolve(ctx stdctx.Context, url string, limit int) (collection *Collection, err error) {
+	kind, id, ok := ParseURL(url)
+	if !ok {
+		return nil, fmt.Errorf("not a spotify track, album or playlist link: %q", url)
+	}
+	if !client.settings.Enabled() {
+		return nil, ErrNotConfigured
+	}
+
+	started := time.Now()
+	defer func() { metrics.ObserveMetadata("spotify", started, err) }()
+
+	switch kind {
+	case "track":
+		return client.track(ctx, id)
+	case "album":
+		return client.album(ctx, id, limit)
+	default:
+		return client.playlist(ctx, id, limit)
+	}
+}
+
+type apiImage struct {
+	URL string `json:"url"`
+}
+
+type apiTrack struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	DurationMS int    `json:"duration_ms"`
+	IsLocal    bool   `json:"is_local"`
+	Artists    []struct {
+		Name string `json:"name"`
+	} `json:"artists"`
+	Album struct {
+		Images []apiImage `json:"images"`
+	} `json:"album"`
+}
+
+// convert an API track, album tracks carry no images so the album's are passed in
+func (raw apiTrack) track(images []apiImage) Track {
+	track := Track{
+		Title:    strings.TrimSpace(raw.Name),
+		Duration: raw.DurationMS / 1000,
+		URL:      CanonicalURL("track", raw.ID),
+	}
+	for _, artist := range raw.Artists {
+		if name := strings.TrimSpace(artist.Name); name != "" {
+			track.Artists = append(track.Artists, name)
+		}
+	}
+	if len(raw.Album.Images) > 0 {
+		images = raw.Album.Images
+	}
+	if len(images) > 0 {
+		// Spotify orders images from largest to smallest
+		track.Thumbnail = images[0].URL
+	}
+	return track
+}
+
+// local files and podcast episodes can't be looked up
+func (raw apiTrack) playable() bool {
+	return raw.ID != "" && !raw.IsLocal && (raw.Type == "" || raw.Type == "track")
+}
+
+func (client *Client) track(ctx stdctx.Context, id string) (*Collection, error) {
+	var raw apiTrack
+	if err := client.get(ctx, "/tracks/"+neturl.PathEscape(id), &raw); err != nil {
+		return nil, err
+	}
+	track := raw.track(nil)
+	return &Collection{Kind: "track", Title: track.Title, Total: 1, Tracks: []Track{track}}, nil
+}
+
+func (client *Client) album(ctx stdctx.Context, id string, limit int) (*Collection, error) {
+	var raw struct {
+		Name   string     `json:"name"`
+		Images []apiImage `json:"images"`
+		Tracks struct {
+			Items []apiTrack `json:"items"`
+			Next  string     `json:"next"`
+			Total int        `json:"total"`
+		} `json:"tracks"`
+	}
+	if err := client.get(ctx, "/albums/"+neturl.PathEscape(id), &raw); err != nil {
+		return nil, err
+	}
+
+	collection := &Collection{Kind: "album", Title: strings.TrimSpace(raw.Name), Total: raw.Tracks.Total}
+	page, next := raw.Tracks.Items, raw.Tracks.Next
+	for {
+		for _, item := range page {
+			if len(collection.Tracks) < limit && item.playable() {
+				collection.Tracks = append(collection.Tracks, item.track(raw.Images))
+			}
+		}
+		if next == "" || len(collection.Tracks) >= limit {
+			break
+		}
+
+		var more struct {
+			Items []apiTrack `json:"items"`
+			Next  string     `json:"next"`
+		}
+		if err := client.get(ctx, next, &more); err != nil {
+			return nil, err
+		}
+		page, next = more.Items, more.Next
+	}
+	return collection, nil
+}
+
+func (client *Client) playlist(ctx stdctx.Context, id string, limit int) (*Collection, error) {
+	type playlistPage struct {
+		Items []struct {
+			Track *apiTrack `json:"track"`
+		} `json:"items"`
+		Next  string `json:"next"`
+		Total int    `json:"total"`
+	}
+	var raw struct {
+		Name   string       `json:"name"`
+		Tracks playlistPage `json:"tracks"`
+	}
+	if err := client.get(ctx, "/playlists/"+neturl.PathEscape(id), &raw); err != nil {
+		return nil, err
+	}
+
+	collection := &Collection{Kind: "playlist", Title: strings.TrimSpace(raw.Name), Total: raw.Tracks.Total}
+	page := raw.Tracks
+	for {
+		for _, item := range page.Items {
+			if len(collection.Tracks) < limit && item.Track != nil && item.Track.playable() {
+				collection.Tracks = append(collection.Tracks, item.Track.track(nil))
+			}
+		}
+		if page.Next == "" || len(collection.Tracks) >= limit {
+			break
+		}
+
+		next := page.Next
+		page = playlistPage{}
+		if err := client.get(ctx, next, &page); err != nil {
+			return nil, err
+		}
+	}
+	return collection, nil
+}
+
+// fetch an API path, or an absolute paging URL, into out
+func (client *Client) get(ctx stdctx.Context, path string, out any) error {
+	token, err := client.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	url := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		url = client.settings.APIURL + path
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	response, err := client.http.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusUnauthorized {
+		// the token was revoked early, fetch a new one next time
+		client.tokenMutex.Lock()
+		client.token = ""
+		client.tokenMutex.Unlock()
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("spotify %s: %s", path, response.Status)
+	}
+	return json.NewDecoder(response.Body).Decode(out)
+}
+
+// return a cached client credentials token, requesting a new one near expiry
+func (client *Client) accessToken(ctx stdctx.Context) (string, error) {
+	client.tokenMutex.Lock()
+	defer client.tokenMutex.Unlock()
+
+	if client.token != "" && time.Now().Before(client.expires) {
+		return client.token, nil
+	}
+
+	form := neturl.Values{"grant_type": {"client_credentials"}}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, client.settings.AccountsURL+"/api/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.SetBasicAuth(client.settings.ClientID, client.settings.ClientSecret)
+
+	response, err := client.http.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("spotify token: %s", response.Status)
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	if body.AccessToken == "" {
+		return "", errors.New("spotify token: empty access token")
+	}
+
+	client.token = body.AccessToken
+	// renew a minute early so requests never race the expiry
+	client.expires = time.Now().Add(time.Duration(body.ExpiresIn)*time.Second - time.Minute)
+	return client.token, nil
+}
@@ -0,0 +1,70 @@
+package spotify
+
+import (
+	stdctx "context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ekkolyth/ekko-bot/internal/config"
+)
+
+func TestResolvePlaylistPagesAndSkipsUnplayable(t *testing.T) {
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/token", func(write http.ResponseWriter, read *http.Request) {
+		if id, secret, ok := read.BasicAuth(); !ok || id != "id" || secret != "secret" {
+			http.Error(write, "bad credentials", http.StatusUnauthorized)
+			return
+		}
+		write.Write([]byte(`{"access_token":"token","expires_in":3600}`))
+	})
+	mux.HandleFunc("GET /v1/playlists/list", func(write http.ResponseWriter, read *http.Request) {
+		if read.Header.Get("Authorization") != "Bearer token" {
+			http.Error(write, "missing token", http.StatusUnauthorized)
+			return
+		}
+		write.Write([]byte(`{"name":"Road Trip","tracks":{"total":4,"next":"` + server.URL + `/v1/playlists/list/tracks?offset=2","items":[
+			{"track":{"id":"a","name":"Song A","type":"track","duration_ms":201500,"artists":[{"name":"One"},{"name":"Two"}],"album":{"images":[{"url":"big.jpg"},{"url":"small.jpg"}]}}},
+			{"track":null}
+		]}}`))
+	})
+	mux.HandleFunc("GET /v1/playlists/list/tracks", func(write http.ResponseWriter, read *http.Request) {
+		write.Write([]byte(`{"next":"","items":[
+			{"track":{"id":"","name":"Local File","is_local":true}},
+			{"track":{"id":"b","name":"Song B","type":"track","duration_ms":90000,"artists":[{"name":"Three"}]}}
+		]}`))
+	})
+	server = httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewClient(config.SpotifySettings{
+		ClientID:     "id",
+		ClientSecret: "secret",
+		APIURL:       server.URL + "/v1",
+		AccountsURL:  server.URL,
+	})
+
+	collection, err := client.Resolve(stdctx.Background(), "https://open.spotify.com/playlist/list?si=abc", 10)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if collection.Title != "Road Trip" || collection.Total != 4 || len(collection.Tracks) != 2 {
+		t.Fatalf("Resolve() = %q total %d with %d tracks; want Road Trip total 4 with 2 tracks", collection.Title, collection.Total, len(collection.Tracks))
+	}
+
+	first := collection.Tracks[0]
+	if first.Artist() != "One, Two" || first.Duration != 201 || first.Thumbnail != "big.jpg" || first.URL != "https://open.spotify.com/track/a" {
+		t.Errorf("first track = %+v", first)
+	}
+	if collection.Tracks[1].Title != "Song B" {
+		t.Errorf("second track = %q; want Song B", collection.Tracks[1].Title)
+	}
+}
+
+func TestResolveWithoutCredentials(t *testing.T) {
+	client := NewClient(config.SpotifySettings{APIURL: "http://127.0.0.1:0"})
+	if _, err := client.Resolve(stdctx.Background(), "https://open.spotify.com/track/a", 1); err != ErrNotConfigured {
+		t.Fatalf("Resolve() error = %v; want ErrNotConfigured", err)
+	}
+}
@@ -0,0 +1,35 @@
+package spotify
+
+import (
+	neturl "net/url"
+	"strings"
+)
+
+// ParseURL returns the kind (track, album or playlist) and ID of an
+// open.spotify.com link. Locale prefixes and tracking parameters are ignored.
+func ParseURL(url string) (kind, id string, ok bool) {
+	parsed, err := neturl.Parse(strings.TrimSpace(url))
+	if err != nil || parsed.Host != "open.spotify.com" {
+		return "", "", false
+	}
+
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	// e.g. /intl-de/track/ID
+	if len(segments) > 0 && strings.HasPrefix(segments[0], "intl-") {
+		segments = segments[1:]
+	}
+	if len(segments) != 2 || segments[1] == "" {
+		return "", "", false
+	}
+
+	switch segments[0] {
+	case "track", "album", "playlist":
+		return segments[0], segments[1], true
+	}
+	return "", "", false
+}
+
+// CanonicalURL returns the link without locale or tracking parameters
+func CanonicalURL(kind, id string) string {
+	return "https://open.spotify.com/" + kind + "/" + id
+}
@@ -0,0 +1,114 @@
+package youtube
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ekkolyth/ekko-bot/internal/logging"
+	"github.com/ekkolyth/ekko-bot/internal/metrics"
+)
+
+// Playlist represents a flattened YouTube playlist
+type Playlist struct {
+	Title   string
+	URL     string
+	Total   int         // number of videos in the playlist, may exceed len(Entries)
+	Entries []VideoInfo // capped at the requested limit
+}
+
+// GetPlaylist lists up to limit videos of a playlist using yt-dlp's flat-playlist mode
+func GetPlaylist(url string, limit int) (*Playlist, error) {
+	return flatPlaylist(url, limit, "playlist")
+}
+
+// SearchVideos lists up to limit search results with their durations, best first
+func SearchVideos(query string, limit int) ([]VideoInfo, error) {
+	results, err := flatPlaylist(fmt.Sprintf("ytsearch%d:%s", limit, query), limit, "search")
+	if err != nil {
+		return nil, err
+	}
+	return results.Entries, nil
+}
+
+// list a playlist or search without resolving each entry, kind labels the metadata metric
+func flatPlaylist(url string, limit int, kind string) (*Playlist, error) {
+	cmd := exec.Command("yt-dlp",
+		"--flat-playlist",
+		"--dump-single-json",
+		"--playlist-end", strconv.Itoa(limit),
+		url,
+	)
+	metrics.ProcessSpawnsTotal.WithLabelValues("yt-dlp").Inc()
+	started := time.Now()
+	output, err := cmd.Output()
+	metrics.ObserveProcess("yt-dlp", started, err)
+	metrics.ObserveMetadata(kind, started, err)
+	if err != nil {
+		logging.Error("Error fetching " + kind + ": " + err.Error())
+		return nil, err
+	}
+
+	var rawPlaylist struct {
+		Title         string `json:"title"`
+		PlaylistCount int    `json:"playlist_count"`
+		Entries       []struct {
+			ID         string  `json:"id"`
+			Title      string  `json:"title"`
+			Channel    string  `json:"channel"`
+			Uploader   string  `json:"uploader"`
+			Duration   float64 `json:"duration"`
+			Thumbnails []struct {
+				URL string `json:"url"`
+			} `json:"thumbnails"`
+		} `json:"entries"`
+	}
+
+	if err := json.Unmarshal(output, &rawPlaylist); err != nil {
+		logging.Error("Error parsing playlist JSON: " + err.Error())
+		return nil, err
+	}
+
+	playlist := &Playlist{
+		Title: strings.TrimSpace(rawPlaylist.Title),
+		URL:   url,
+		Total: rawPlaylist.PlaylistCount,
+	}
+
+	for _, entry := range rawPlaylist.Entries {
+		if entry.ID == "" || len(playlist.Entries) >= limit {
+			continue
+		}
+
+		artist := entry.Channel
+		if artist == "" {
+			artist = entry.Uploader
+		}
+
+		thumbnail := ""
+		if len(entry.Thumbnails) > 0 {
+			// yt-dlp orders thumbnails from smallest to largest
+			thumbnail = entry.Thumbnails[len(entry.Thumbnails)-1].URL
+		}
+
+		playlist.Entries = append(playlist.Entries, VideoInfo{
+			Title:     strings.TrimSpace(entry.Title),
+			URL:       "https://www.youtube.com/watch?v=" + entry.ID,
+			Artist:    strings.TrimSpace(artist),
+			Duration:  int(entry.Duration),
+			Thumbnail: thumbnail,
+		})
+	}
+
+	if len(playlist.Entries) == 0 {
+		return nil, fmt.Errorf("playlist %s has no playable entries", url)
+	}
+	if playlist.Total < len(playlist.Entries) {
+		playlist.Total = len(playlist.Entries)
+	}
+
+	return playlist, nil
+}
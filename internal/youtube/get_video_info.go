@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/ekkolyth/ekko-bot/internal/logging"
+	"github.com/ekkolyth/ekko-bot/internal/metrics"
 )
 
 // VideoInfo represents metadata about a video
@@ -15,13 +17,18 @@ type VideoInfo struct {
 	Artist    string `json:"artist"`
 	Duration  int    `json:"duration"`
 	Thumbnail string `json:"thumbnail"`
+	Live      bool   `json:"live"`
 }
 
 // GetVideoInfo fetches metadata for a YouTube video URL using yt-dlp
 func GetVideoInfo(url string) (*VideoInfo, error) {
 	// Use yt-dlp to get video info in JSON format
 	cmd := exec.Command("yt-dlp", "--dump-json", "--no-playlist", url)
+	metrics.ProcessSpawnsTotal.WithLabelValues("yt-dlp").Inc()
+	started := time.Now()
 	output, err := cmd.Output()
+	metrics.ObserveProcess("yt-dlp", started, err)
+	metrics.ObserveMetadata("video", started, err)
 	if err != nil {
 		logging.Error("Error fetching video info: " + err.Error())
 		return nil, err
@@ -36,6 +43,8 @@ func GetVideoInfo(url string) (*VideoInfo, error) {
 		Duration  int    `json:"duration"`
 		Thumbnail string `json:"thumbnail"`
 		WebpageURL string `json:"webpage_url"`
+		IsLive     bool   `json:"is_live"`
+		LiveStatus string `json:"live_status"`
 	}
 
 	if err := json.Unmarshal(output, &rawInfo); err != nil {
@@ -58,6 +67,7 @@ func GetVideoInfo(url string) (*VideoInfo, error) {
 		Artist:    strings.TrimSpace(artist),
 		Duration:  rawInfo.Duration,
 		Thumbnail: rawInfo.Thumbnail,
+		Live:      rawInfo.IsLive || rawInfo.LiveStatus == "is_live",
 	}
 
 	return info, nil
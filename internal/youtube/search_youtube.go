@@ -4,16 +4,23 @@ import (
 	"bytes"
 	"os/exec"
 	"strings"
+	"time"
 
-	"github.com/ekkolyth/ekko-bot/internal/api/httpx"
 	"github.com/ekkolyth/ekko-bot/internal/logging"
+	"github.com/ekkolyth/ekko-bot/internal/metrics"
 )
 
-func SearchYoutube(query string) (string, bool) {
-	cmd := exec.Command("yt-dlp", "--flat-playlist", "--get-url", "ytsearch1:"+query)
+// Search returns the URL of the first result from one of yt-dlp's search
+// extractors, e.g. "ytsearch1" or "scsearch1"
+func Search(extractor, query string) (string, bool) {
+	cmd := exec.Command("yt-dlp", "--flat-playlist", "--get-url", extractor+":"+query)
 	var outputFromSearch bytes.Buffer
 	cmd.Stdout = &outputFromSearch
+	metrics.ProcessSpawnsTotal.WithLabelValues("yt-dlp").Inc()
+	started := time.Now()
 	err := cmd.Run()
+	metrics.ObserveProcess("yt-dlp", started, err)
+	metrics.ObserveMetadata("search", started, err)
 	if err != nil {
 		logging.Error("Error: " + err.Error())
 		return "", false
@@ -29,11 +36,5 @@ func SearchYoutube(query string) (string, bool) {
 		return "", false
 	}
 
-	// sanity check with validating the url
-
-	if !httpx.IsValidURL(url) {
-		return "", false
-	}
-
 	return url, true
 }
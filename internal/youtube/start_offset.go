@@ -0,0 +1,47 @@
+package youtube
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var timestampPattern = regexp.MustCompile(`^(?:(\d+)h)?(?:(\d+)m)?(?:(\d+)s?)?$`)
+
+// StartOffset returns the start time encoded in a video URL's t= or start= parameter
+// Accepts plain seconds ("90", "90s") and YouTube's "1h2m3s" form; returns 0 if none is set
+func StartOffset(videoURL string) time.Duration {
+	parsed, err := url.Parse(videoURL)
+	if err != nil {
+		return 0
+	}
+
+	query := parsed.Query()
+	value := query.Get("t")
+	if value == "" {
+		value = query.Get("start")
+	}
+	if value == "" {
+		return 0
+	}
+
+	match := timestampPattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0
+	}
+
+	var offset time.Duration
+	units := []time.Duration{time.Hour, time.Minute, time.Second}
+	for i, unit := range units {
+		if match[i+1] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(match[i+1])
+		if err != nil {
+			return 0
+		}
+		offset += time.Duration(n) * unit
+	}
+	return offset
+}
@@ -0,0 +1,78 @@
+import { createHmac } from 'node:crypto';
+import { and, eq } from 'drizzle-orm';
+
+import * as authSchema from '@/db/auth-schema';
+import { db } from '@/lib/auth/auth';
+import { baseURL } from '@/lib/base-url';
+
+// Tokens only need to outlive a single proxied request
+const TOKEN_TTL_SECONDS = 60;
+
+// Discord account last linked on the API for each app user
+const linkedAccounts = new Map<string, string>();
+
+function base64url(input: string): string {
+  return Buffer.from(input).toString('base64url');
+}
+
+/**
+ * Signs a short-lived HS256 JWT for the Go API using API_AUTH_SECRET.
+ * The API resolves `sub` to a Discord user through user_discord_account,
+ * which linkApiAccount fills in from `discord_user_id`.
+ */
+export function signApiToken(appUserId: string, discordUserId?: string | null): string {
+  const secret = process.env.API_AUTH_SECRET;
+  if (!secret) {
+    throw new Error('API_AUTH_SECRET not set');
+  }
+
+  const now = Math.floor(Date.now() / 1000);
+  const header = base64url(JSON.stringify({ alg: 'HS256', typ: 'JWT' }));
+  const payload = base64url(
+    JSON.stringify({
+      sub: appUserId,
+      discord_user_id: discordUserId ?? undefined,
+      iat: now,
+      exp: now + TOKEN_TTL_SECONDS,
+    })
+  );
+  const signature = createHmac('sha256', secret).update(`${header}.${payload}`).digest('base64url');
+
+  return `${header}.${payload}.${signature}`;
+}
+
+/**
+ * Authorization header for requests to the Go API on behalf of an app user
+ */
+export async function apiAuthHeaders(appUserId: string): Promise<Record<string, string>> {
+  const [discordAccount] = await db
+    .select({ accountId: authSchema.account.accountId })
+    .from(authSchema.account)
+    .where(and(eq(authSchema.account.userId, appUserId), eq(authSchema.account.providerId, 'discord')))
+    .limit(1);
+
+  const token = signApiToken(appUserId, discordAccount?.accountId);
+  if (discordAccount?.accountId) {
+    await linkApiAccount(appUserId, discordAccount.accountId, token);
+  }
+
+  return { Authorization: `Bearer ${token}` };
+}
+
+/**
+ * Links the app user to their Discord account on the API the first time a token
+ * is issued for them, so the API never writes while authenticating requests
+ */
+async function linkApiAccount(appUserId: string, discordUserId: string, token: string): Promise<void> {
+  if (!baseURL || linkedAccounts.get(appUserId) === discordUserId) {
+    return;
+  }
+
+  const response = await fetch(`${baseURL}/api/auth/link`, {
+    method: 'POST',
+    headers: { Authorization: `Bearer ${token}` },
+  });
+  if (response.ok) {
+    linkedAccounts.set(appUserId, discordUserId);
+  }
+}
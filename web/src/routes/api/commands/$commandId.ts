@@ -2,6 +2,7 @@ import { createFileRoute } from '@tanstack/react-router';
 import { json } from '@tanstack/react-start';
 
 import { auth } from '@/lib/auth/auth';
+import { apiAuthHeaders } from '@/lib/api-token';
 import { baseURL } from '@/lib/base-url';
 
 interface CommandRequestBody {
@@ -31,7 +32,7 @@ export const Route = createFileRoute('/api/commands/$commandId')({
           const apiURL = `${baseURL}/api/commands/${params.commandId}`;
           const response = await fetch(apiURL, {
             method: 'PATCH',
-            headers: { 'Content-Type': 'application/json' },
+            headers: { 'Content-Type': 'application/json', ...(await apiAuthHeaders(session.user.id)) },
             body: JSON.stringify({
               name: body.name,
               response: body.response,
@@ -63,7 +64,7 @@ export const Route = createFileRoute('/api/commands/$commandId')({
           const apiURL = `${baseURL}/api/commands/${params.commandId}`;
           const response = await fetch(apiURL, {
             method: 'DELETE',
-            headers: { 'Content-Type': 'application/json' },
+            headers: { 'Content-Type': 'application/json', ...(await apiAuthHeaders(session.user.id)) },
           });
 
           if (!response.ok) {
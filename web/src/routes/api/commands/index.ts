@@ -2,6 +2,7 @@ import { createFileRoute } from '@tanstack/react-router';
 import { json } from '@tanstack/react-start';
 
 import { auth } from '@/lib/auth/auth';
+import { apiAuthHeaders } from '@/lib/api-token';
 import { baseURL } from '@/lib/base-url';
 
 interface CommandRequestBody {
@@ -26,7 +27,7 @@ export const Route = createFileRoute('/api/commands/')({
           const apiURL = `${baseURL}/api/commands`;
           const response = await fetch(apiURL, {
             method: 'GET',
-            headers: { 'Content-Type': 'application/json' },
+            headers: { 'Content-Type': 'application/json', ...(await apiAuthHeaders(session.user.id)) },
           });
 
           if (!response.ok) {
@@ -59,7 +60,7 @@ export const Route = createFileRoute('/api/commands/')({
           const apiURL = `${baseURL}/api/commands`;
           const response = await fetch(apiURL, {
             method: 'POST',
-            headers: { 'Content-Type': 'application/json' },
+            headers: { 'Content-Type': 'application/json', ...(await apiAuthHeaders(session.user.id)) },
             body: JSON.stringify({
               name: body.name,
               response: body.response,
@@ -1,6 +1,7 @@
 import { createFileRoute } from '@tanstack/react-router'
 import { json } from '@tanstack/react-start'
 import { auth } from '@/lib/auth/auth'
+import { apiAuthHeaders } from '@/lib/api-token'
 import { baseURL } from '@/lib/base-url'
 import { getDiscordFromSession } from '@/lib/get-discord-from-session'
 
@@ -27,7 +28,7 @@ export const Route = createFileRoute('/api/guilds/current/voice')({
         )}`
 
         try {
-          const response = await fetch(apiURL)
+          const response = await fetch(apiURL, { headers: await apiAuthHeaders(session.user.id) })
           if (!response.ok) {
             const detail = await response.text().catch(() => '')
             return json(
@@ -1,6 +1,7 @@
 import { createFileRoute } from '@tanstack/react-router';
 import { json } from '@tanstack/react-start';
 import { auth } from '@/lib/auth/auth';
+import { apiAuthHeaders } from '@/lib/api-token';
 import { baseURL } from '@/lib/base-url';
 import { getDiscordFromSession } from '@/lib/get-discord-from-session';
 
@@ -38,6 +39,7 @@ export const Route = createFileRoute('/api/queue/')({
             method: 'GET',
             headers: {
               'Content-Type': 'application/json',
+              ...(await apiAuthHeaders(session.user.id)),
             },
           });
 
@@ -99,6 +101,7 @@ export const Route = createFileRoute('/api/queue/')({
             method: 'POST',
             headers: {
               'Content-Type': 'application/json',
+              ...(await apiAuthHeaders(session.user.id)),
             },
             body: JSON.stringify({
               discord_user_id: discordUserId,
@@ -1,6 +1,7 @@
 import { createFileRoute } from '@tanstack/react-router';
 import { json } from '@tanstack/react-start';
 import { auth } from '@/lib/auth/auth';
+import { apiAuthHeaders } from '@/lib/api-token';
 import { baseURL } from '@/lib/base-url';
 
 export const Route = createFileRoute('/api/queue/remove/')({
@@ -30,6 +31,7 @@ export const Route = createFileRoute('/api/queue/remove/')({
             method: 'POST',
             headers: {
               'Content-Type': 'application/json',
+              ...(await apiAuthHeaders(session.user.id)),
             },
             body: JSON.stringify(requestBody),
           });
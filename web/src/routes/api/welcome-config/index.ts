@@ -2,6 +2,7 @@ import { createFileRoute } from '@tanstack/react-router';
 import { json } from '@tanstack/react-start';
 
 import { auth } from '@/lib/auth/auth';
+import { apiAuthHeaders } from '@/lib/api-token';
 import { baseURL } from '@/lib/base-url';
 
 interface WelcomeConfigRequest {
@@ -26,7 +27,7 @@ export const Route = createFileRoute('/api/welcome-config/')({
         try {
           const response = await fetch(`${baseURL}/api/welcome-config`, {
             method: 'GET',
-            headers: { 'Content-Type': 'application/json' },
+            headers: { 'Content-Type': 'application/json', ...(await apiAuthHeaders(session.user.id)) },
           });
 
           if (!response.ok) {
@@ -58,7 +59,7 @@ export const Route = createFileRoute('/api/welcome-config/')({
         try {
           const response = await fetch(`${baseURL}/api/welcome-config`, {
             method: 'PUT',
-            headers: { 'Content-Type': 'application/json' },
+            headers: { 'Content-Type': 'application/json', ...(await apiAuthHeaders(session.user.id)) },
             body: JSON.stringify(body),
           });
 